@@ -4,10 +4,13 @@ import (
 	"container/heap"
 	"encoding/json"
 	"go/ast"
+	"go/doc"
 	"go/token"
 	"go/types"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/ezraisw/go-selectivetesting/internal/util"
@@ -15,11 +18,56 @@ import (
 )
 
 type definition struct {
+	// Both obj and node are nil for definitions restored from cache.
 	obj            types.Object
-	fileName       string
 	node           ast.Node
+	pkgPath        string
+	name           string
+	fileName       string
+	startLine      int
+	endLine        int
 	usedByObjNames util.Set[string]
 	usingObjNames  util.Set[string]
+	// Whether the definition runs when its package is loaded, such as init functions.
+	sideEffect bool
+	// Set for Ginkgo containers and specs, which have no objects of their own.
+	ginkgoNode *GinkgoNode
+	// Object name of the parent test for subtests, which have no objects of their own.
+	subtestOf string
+	// Files embedded into the variable through go:embed directives.
+	embedFiles []string
+	// String literals within definitions of test files, telling which testdata files they use.
+	stringLits util.Set[string]
+	// Whether the definition uses objects generated by cgo, such as C functions and types.
+	usesCgo bool
+	// Names of the build profiles compiling the definition, empty without any profile.
+	profiles util.Set[string]
+	// Locations of the same object compiled from other files under other build profiles, such as x_windows.go next to
+	// x_linux.go.
+	otherLocations []definitionLocation
+}
+
+// definitionLocation is where a definition is declared. Fields are exported to be stored in the cache.
+type definitionLocation struct {
+	FileName  string
+	StartLine int
+	EndLine   int
+}
+
+// linesIn returns the lines of the definition within the file, which is not its own when it is one of the other
+// locations.
+func (def *definition) linesIn(fileName string) (int, int) {
+	for _, loc := range def.otherLocations {
+		if loc.FileName == fileName {
+			return loc.StartLine, loc.EndLine
+		}
+	}
+	return def.startLine, def.endLine
+}
+
+// isNested reports whether the definition is a node nested in a test, such as a subtest or a Ginkgo spec.
+func (def *definition) isNested() bool {
+	return def.ginkgoNode != nil || def.subtestOf != ""
 }
 
 type MiscUser struct {
@@ -35,28 +83,128 @@ type MiscUsage struct {
 }
 
 type TestedPackage struct {
+	// Selected functions of each kind, "*" meaning all of them.
 	Names      util.Set[string]
+	Benchmarks util.Set[string]
+	Fuzzes     util.Set[string]
+	Examples   util.Set[string]
+	// Selected test methods of the testify suites run by the selected tests. Tests running every method of their
+	// suites are left out.
+	SuiteMethods map[string]util.Set[string]
+	// Selected Ginkgo containers and specs by the tests running them. Tests running every spec are left out.
+	GinkgoNodes map[string]util.Set[GinkgoNode]
+	// Selected subtests by their parent tests. Tests running every subtest are left out.
+	Subtests map[string]util.Set[string]
+	// Seed corpus files among the notable files by the fuzz targets using them, relative to the package directory.
+	FuzzCorpora map[string]util.Set[string]
+	// Shortest usage chains from a notable object to each selected test, "*" for chains selecting every test of the
+	// package. Tests selected through changed modules have none.
+	Reasons    map[string][]Hop
 	HasNotable bool
+	Module     string
+	// Whether every test is selected because the TestMain of the package has been reached.
+	ByTestMain bool
+}
+
+func (tp *TestedPackage) addName(name string) {
+	names := tp.namesOf(testKindOf(name))
+	// Everything is already included.
+	if names.Has("*") {
+		return
+	}
+	names.Add(name)
+}
+
+// Has reports whether the test, benchmark, fuzz target or example is selected.
+func (tp *TestedPackage) Has(name string) bool {
+	names := tp.namesOf(testKindOf(name))
+	return names.Has("*") || names.Has(name)
+}
+
+func (tp *TestedPackage) setAll(kinds []testKind) {
+	for _, kind := range kinds {
+		*tp.namesOf(kind) = util.NewSet("*")
+		if kind == testKindTest {
+			tp.SuiteMethods = make(map[string]util.Set[string])
+			tp.GinkgoNodes = make(map[string]util.Set[GinkgoNode])
+			tp.Subtests = make(map[string]util.Set[string])
+		}
+	}
+}
+
+// Hop is a definition within the usage chain leading to a selected test.
+type Hop struct {
+	ObjName   string
+	FileName  string
+	StartLine int
+}
+
+func (tp *TestedPackage) addReason(name string, chain []Hop) {
+	// The first chain found is the shortest.
+	if _, ok := tp.Reasons[name]; ok {
+		return
+	}
+	tp.Reasons[name] = chain
+}
+
+type NotableRange struct {
+	FileName  string
+	StartLine int
+	EndLine   int
 }
 
 type FileAnalyzer struct {
-	basePkg          string
+	// Base packages of every module in question, the first one being the main module.
+	basePkgs         []string
 	notableFileNames util.Set[string]
+	notableRanges    map[string][]NotableRange
 
 	moduleDir  string
 	patterns   []string
 	depth      int
 	buildFlags []string
+	profiles   []BuildProfile
 	miscUsages []MiscUsage
 	testAll    bool
-
-	pkgDirs          map[string]string
-	testFuncs        util.Set[*types.Func]
+	cachePath  string
+
+	// Files that no longer exist, loaded on top of the packages they used to belong to.
+	removedFiles map[string][]byte
+	// Contents of notable files in the base revision, for semantic comparison.
+	baseFiles map[string][]byte
+	// Third-party modules whose versions changed.
+	changedModules []string
+
+	pkgDirs       map[string]string
+	testFuncs     util.Set[string]
+	testMainFuncs util.Set[string]
+	// Reasons why functions looking like tests are not run by go test.
+	rejectedTestFuncs map[string]string
+	// Testify suites by the object names of the tests running them.
+	suites map[string]*testifySuite
+	// Object names of the tests running the Ginkgo specs of their packages.
+	ginkgoBootstraps util.Set[string]
 	definitions      map[string]*definition
 	pkgTestUniqNames map[string]util.Set[string]
 	pkgObjNames      map[string]util.Set[string]
 	pkgLocalObjNames map[string]map[string]string
 	fileObjNames     map[string]util.Set[string]
+	fileHeaderLines  map[string]int
+	pkgImports       map[string]util.Set[string]
+	// Implementing methods by the interface methods dispatching to them.
+	dispatches map[string]util.Set[string]
+	// Packages owning the files that are not compiled as Go, such as cgo sources and build-excluded files.
+	otherFilePkgs map[string]string
+	// Name of the build profile whose packages are being analyzed.
+	loadingProfile string
+	// Objects reached by the last search of tests by the objects they were reached from, empty for notable objects.
+	reachedFrom map[string]string
+
+	// Struct types declaring each field, filled per package on demand.
+	fieldOwners    map[*types.Var]*types.TypeName
+	fieldOwnerPkgs util.Set[*types.Package]
+	// Names of objects that cannot be told apart by their object strings, such as init functions.
+	uniqObjNames map[types.Object]string
 }
 
 var defaultOptions = []Option{
@@ -66,15 +214,28 @@ var defaultOptions = []Option{
 
 func NewFileAnalyzer(basePkg string, notableFileNames []string, options ...Option) *FileAnalyzer {
 	fa := &FileAnalyzer{
-		basePkg:          basePkg,
-		notableFileNames: util.SetFrom(notableFileNames),
-		pkgDirs:          make(map[string]string),
-		testFuncs:        util.NewSet[*types.Func](),
-		definitions:      make(map[string]*definition),
-		pkgTestUniqNames: make(map[string]util.Set[string]),
-		pkgObjNames:      make(map[string]util.Set[string]),
-		pkgLocalObjNames: make(map[string]map[string]string),
-		fileObjNames:     make(map[string]util.Set[string]),
+		basePkgs:          []string{basePkg},
+		notableFileNames:  util.SetFrom(notableFileNames),
+		notableRanges:     make(map[string][]NotableRange),
+		pkgDirs:           make(map[string]string),
+		testFuncs:         util.NewSet[string](),
+		testMainFuncs:     util.NewSet[string](),
+		rejectedTestFuncs: make(map[string]string),
+		suites:            make(map[string]*testifySuite),
+		ginkgoBootstraps:  util.NewSet[string](),
+		definitions:       make(map[string]*definition),
+		pkgTestUniqNames:  make(map[string]util.Set[string]),
+		pkgObjNames:       make(map[string]util.Set[string]),
+		pkgLocalObjNames:  make(map[string]map[string]string),
+		fileObjNames:      make(map[string]util.Set[string]),
+		fileHeaderLines:   make(map[string]int),
+		pkgImports:        make(map[string]util.Set[string]),
+		dispatches:        make(map[string]util.Set[string]),
+		otherFilePkgs:     make(map[string]string),
+		reachedFrom:       make(map[string]string),
+		fieldOwners:       make(map[*types.Var]*types.TypeName),
+		fieldOwnerPkgs:    util.NewSet[*types.Package](),
+		uniqObjNames:      make(map[types.Object]string),
 	}
 
 	fa.applyOptions(defaultOptions)
@@ -89,66 +250,231 @@ func (fa *FileAnalyzer) applyOptions(options []Option) {
 	}
 }
 
-func (fa *FileAnalyzer) addDefinition(obj types.Object, fileName string, node ast.Node) {
-	fa.definitions[types.ObjectString(obj, nil)] = &definition{
+func (fa *FileAnalyzer) isBasePkg(pkgPath string) bool {
+	return fa.moduleOf(pkgPath) != ""
+}
+
+// moduleOf returns the most specific base package containing the package, as modules may be nested.
+func (fa *FileAnalyzer) moduleOf(pkgPath string) string {
+	module := ""
+	for _, basePkg := range fa.basePkgs {
+		if util.IsSubPackage(basePkg, pkgPath) && len(basePkg) > len(module) {
+			module = basePkg
+		}
+	}
+	return module
+}
+
+func (fa *FileAnalyzer) addDefinition(pkgPath string, obj types.Object, fileName string, node ast.Node, startLine, endLine int) {
+	fa.putDefinition(fa.objNameOf(obj), &definition{
 		obj:            obj,
-		fileName:       fileName,
 		node:           node,
+		pkgPath:        pkgPath,
+		name:           fa.localNameOf(obj),
+		fileName:       fileName,
+		startLine:      startLine,
+		endLine:        endLine,
 		usedByObjNames: util.NewSet[string](),
 		usingObjNames:  util.NewSet[string](),
+	})
+}
+
+func (fa *FileAnalyzer) putDefinition(objName string, def *definition) {
+	if fa.loadingProfile != "" {
+		if def.profiles == nil {
+			def.profiles = util.NewSet[string]()
+		}
+		def.profiles.Add(fa.loadingProfile)
+	}
+
+	// Definitions compiled under several build profiles keep what was found under the others.
+	if existing, ok := fa.definitions[objName]; ok {
+		def.usedByObjNames.AddFrom(existing.usedByObjNames)
+		def.usingObjNames.AddFrom(existing.usingObjNames)
+		if existing.profiles != nil {
+			if def.profiles == nil {
+				def.profiles = util.NewSet[string]()
+			}
+			def.profiles.AddFrom(existing.profiles)
+		}
+
+		// Per-platform files declare the same object under different build profiles. The first location stays the one
+		// reported, and ranges are matched against all of them.
+		def.otherLocations = append(def.otherLocations, existing.otherLocations...)
+		if existing.fileName != def.fileName {
+			loc := definitionLocation{FileName: def.fileName, StartLine: def.startLine, EndLine: def.endLine}
+			def.fileName, def.startLine, def.endLine = existing.fileName, existing.startLine, existing.endLine
+			def.otherLocations = slices.DeleteFunc(def.otherLocations, func(other definitionLocation) bool {
+				return other.FileName == loc.FileName
+			})
+			def.otherLocations = append(def.otherLocations, loc)
+		}
+	}
+
+	fa.definitions[objName] = def
+
+	pkgObjs := util.MapGetOrCreate(fa.pkgObjNames, def.pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+	pkgObjs.Add(objName)
+
+	// Names of nested nodes are not those of package objects.
+	if !def.isNested() {
+		pkgLocalObjs := util.MapGetOrCreate(fa.pkgLocalObjNames, def.pkgPath, func() map[string]string { return make(map[string]string) })
+		pkgLocalObjs[def.name] = objName
+	}
+
+	fileObjs := util.MapGetOrCreate(fa.fileObjNames, def.fileName, func() util.Set[string] { return util.NewSet[string]() })
+	fileObjs.Add(objName)
+	for _, loc := range def.otherLocations {
+		otherFileObjs := util.MapGetOrCreate(fa.fileObjNames, loc.FileName, func() util.Set[string] { return util.NewSet[string]() })
+		otherFileObjs.Add(objName)
 	}
 }
 
+func (fa *FileAnalyzer) addTestFunc(objName string) {
+	def := fa.definitions[objName]
+	fa.testFuncs.Add(objName)
+	uniqNames := util.MapGetOrCreate(fa.pkgTestUniqNames, def.pkgPath, func() util.Set[string] {
+		return util.NewSet[string]()
+	})
+	uniqNames.Add(def.name)
+}
+
 func (fa *FileAnalyzer) getDefinition(obj types.Object) *definition {
-	return fa.definitions[types.ObjectString(obj, nil)]
+	return fa.definitions[fa.objNameOf(obj)]
 }
 
-func (fa *FileAnalyzer) addObj(pkgPath, fileName string, obj types.Object) {
-	objName := types.ObjectString(obj, nil)
+// objNameOf returns the name identifying the object in the graph. Object strings of fields do not tell which struct
+// they belong to, so fields of top-level struct types are qualified with it.
+func (fa *FileAnalyzer) objNameOf(obj types.Object) string {
+	if objName, ok := fa.uniqObjNames[obj]; ok {
+		return objName
+	}
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		if owner, ok := fa.fieldOwnerOf(v); ok {
+			return "field " + owner.Pkg().Path() + "." + owner.Name() + "." + v.Name()
+		}
+	}
+	return types.ObjectString(obj, nil)
+}
 
-	pkgObjs := util.MapGetOrCreate(fa.pkgObjNames, pkgPath, func() util.Set[string] { return util.NewSet[string]() })
-	pkgObjs.Add(objName)
+func (fa *FileAnalyzer) localNameOf(obj types.Object) string {
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		if owner, ok := fa.fieldOwnerOf(v); ok {
+			return owner.Name() + "." + v.Name()
+		}
+	}
+	return obj.Name()
+}
 
-	pkgLocalObjs := util.MapGetOrCreate(fa.pkgLocalObjNames, pkgPath, func() map[string]string { return make(map[string]string) })
-	pkgLocalObjs[obj.Name()] = objName
+// fieldOwnerOf returns the top-level struct type declaring the field. Fields of anonymous structs have none.
+func (fa *FileAnalyzer) fieldOwnerOf(v *types.Var) (*types.TypeName, bool) {
+	v = v.Origin()
+	if v.Pkg() == nil {
+		return nil, false
+	}
 
-	fileObjs := util.MapGetOrCreate(fa.fileObjNames, fileName, func() util.Set[string] { return util.NewSet[string]() })
-	fileObjs.Add(objName)
+	if !fa.fieldOwnerPkgs.Has(v.Pkg()) {
+		fa.fieldOwnerPkgs.Add(v.Pkg())
+
+		scope := v.Pkg().Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || typeName.IsAlias() {
+				continue
+			}
+			st, ok := typeName.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				field := st.Field(i)
+				// Types defined from another struct type share its fields, which are owned by the closest
+				// declaration preceding them.
+				if typeName.Pos() > field.Pos() {
+					continue
+				}
+				if owner, ok := fa.fieldOwners[field]; ok && owner.Pos() > typeName.Pos() {
+					continue
+				}
+				fa.fieldOwners[field] = typeName
+			}
+		}
+	}
+
+	owner, ok := fa.fieldOwners[v]
+	return owner, ok
 }
 
 func (fa *FileAnalyzer) Load() error {
-	pkgs, err := packages.Load(&packages.Config{
+	if fa.cachePath != "" {
+		return fa.loadWithCache()
+	}
+
+	return fa.loadProfiles(fa.patterns...)
+}
+
+// loadProfiles analyzes the packages under every build profile into the same graph.
+func (fa *FileAnalyzer) loadProfiles(patterns ...string) error {
+	defer func() { fa.loadingProfile = "" }()
+
+	for _, profile := range fa.buildProfiles() {
+		fa.loadingProfile = profile.Name
+
+		pkgs, err := fa.loadPackages(profile, patterns...)
+		if err != nil {
+			return err
+		}
+
+		fa.analyzePackages(pkgs)
+	}
+
+	return nil
+}
+
+func (fa *FileAnalyzer) loadPackages(profile BuildProfile, patterns ...string) ([]*packages.Package, error) {
+	return packages.Load(&packages.Config{
 		Dir: fa.moduleDir,
 		Mode: packages.NeedCompiledGoFiles |
 			packages.NeedDeps |
+			packages.NeedEmbedFiles |
 			packages.NeedFiles |
 			packages.NeedImports |
 			packages.NeedName |
 			packages.NeedSyntax |
 			packages.NeedTypes |
 			packages.NeedTypesInfo,
-		BuildFlags: fa.buildFlags,
+		BuildFlags: fa.BuildFlagsOf(profile),
+		Env:        envOf(profile),
 		Tests:      true,
-	}, fa.patterns...)
-	if err != nil {
-		return err
-	}
+		Overlay:    fa.removedFiles,
+	}, patterns...)
+}
 
+func (fa *FileAnalyzer) analyzePackages(pkgs []*packages.Package) {
 	for _, pkg := range pkgs {
 		fa.addPkgPath(pkg)
+		fa.addOtherFiles(pkg)
 	}
 
+	// Dependencies outside of the module are needed to know who is affected by their changes.
+	packages.Visit(pkgs, nil, fa.addPkgImports)
+
 	for _, pkg := range pkgs {
 		fa.searchTopLevelObjects(pkg)
+		fa.searchGinkgoNodes(pkg)
+		fa.searchSubtests(pkg)
+		fa.searchEmbeds(pkg)
 	}
 
 	for _, pkg := range pkgs {
 		fa.analyzeUses(pkg)
 		fa.analyzeDefs(pkg)
 		fa.analyzeImplicits(pkg)
+		fa.analyzeTestRunners(pkg)
+		fa.searchStringLits(pkg)
 	}
 
-	return nil
+	fa.analyzeDispatches(pkgs)
 }
 
 func (fa *FileAnalyzer) addPkgPath(pkg *packages.Package) {
@@ -166,28 +492,64 @@ func (fa *FileAnalyzer) addPkgPath(pkg *packages.Package) {
 	fa.pkgDirs[pkgPath] = dir
 }
 
+func (fa *FileAnalyzer) addPkgImports(pkg *packages.Package) {
+	if strings.HasSuffix(pkg.PkgPath, ".test") {
+		return
+	}
+	pkgPath := strings.TrimSuffix(pkg.PkgPath, "_test")
+
+	imports := util.MapGetOrCreate(fa.pkgImports, pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+	for _, imp := range pkg.Imports {
+		imports.Add(imp.PkgPath)
+	}
+}
+
 func (fa *FileAnalyzer) searchTopLevelObjects(pkg *packages.Package) {
 	// Collect all nodes from top level declarations.
 	// There aren't any good way to obtain AST position from object.
-	nodes := make([]ast.Node, 0)
+	nodes := make([]topLevelNode, 0)
+	runnableExamples := util.NewSet[string]()
 	for _, astFile := range pkg.Syntax {
+		fa.recordFileHeader(pkg.Fset, astFile)
+
+		if strings.HasSuffix(pkg.Fset.File(astFile.Pos()).Name(), "_test.go") {
+			for _, example := range doc.Examples(astFile) {
+				if example.Output != "" || example.EmptyOutput {
+					runnableExamples.Add("Example" + example.Name)
+				}
+			}
+		}
+
 		for _, d := range astFile.Decls {
 			switch decl := d.(type) {
 			case *ast.FuncDecl:
-				nodes = append(nodes, decl)
+				nodes = append(nodes, topLevelNode{node: decl, start: docStart(decl.Doc, decl.Pos())})
 			case *ast.GenDecl:
 				for _, s := range decl.Specs {
+					// Ungrouped declarations own their keyword and doc comment.
+					start := s.Pos()
+					if !decl.Lparen.IsValid() {
+						start = docStart(decl.Doc, decl.Pos())
+					}
+
 					if ts, ok := s.(*ast.TypeSpec); ok {
-						if iface, ok := ts.Type.(*ast.InterfaceType); ok {
-							nodes = append(nodes, ts.Name)
-							for _, method := range iface.Methods.List {
-								nodes = append(nodes, method)
+						switch typ := ts.Type.(type) {
+						case *ast.InterfaceType:
+							nodes = append(nodes, topLevelNode{node: ts.Name, start: start})
+							for _, method := range typ.Methods.List {
+								nodes = append(nodes, topLevelNode{node: method, start: docStart(method.Doc, method.Pos())})
+							}
+						case *ast.StructType:
+							// Fields come first so that they are matched before the enclosing type.
+							for _, field := range typ.Fields.List {
+								nodes = append(nodes, topLevelNode{node: field, start: docStart(field.Doc, field.Pos())})
 							}
-						} else {
-							nodes = append(nodes, s)
+							nodes = append(nodes, topLevelNode{node: s, start: start})
+						default:
+							nodes = append(nodes, topLevelNode{node: s, start: start})
 						}
 					} else {
-						nodes = append(nodes, s)
+						nodes = append(nodes, topLevelNode{node: s, start: start})
 					}
 				}
 			}
@@ -199,7 +561,7 @@ func (fa *FileAnalyzer) searchTopLevelObjects(pkg *packages.Package) {
 			continue
 		}
 
-		isMemberFunc := false
+		isMember := false
 		switch obj := defObj.(type) {
 		case *types.Func:
 			sig := obj.Type().(*types.Signature)
@@ -207,51 +569,117 @@ func (fa *FileAnalyzer) searchTopLevelObjects(pkg *packages.Package) {
 			if sig.Recv() != nil {
 				switch sig.Recv().Type().(type) {
 				case *types.Pointer, *types.Named, *types.Interface:
-					isMemberFunc = true
+					isMember = true
 				}
 			}
+		case *types.Var:
+			if obj.IsField() {
+				_, isMember = fa.fieldOwnerOf(obj)
+			}
 		}
 
-		// Ignore non top-level objects that are not methods of a struct/interface.
-		if !isMemberFunc && defObj.Parent() != pkg.Types.Scope() {
+		// Ignore non top-level objects that are not methods of a struct/interface or fields of a struct.
+		if !isMember && defObj.Parent() != pkg.Types.Scope() {
 			continue
 		}
 
-		file := pkg.Fset.File(ident.Pos())
+		position := sourcePositionOf(pkg.Fset, ident.Pos())
 
 		// Prevent object definitions from cache files.
-		if util.IsWithinPath(util.GoCacheFolder(), file.Name()) {
+		if util.IsWithinPath(util.GoCacheFolder(), position.Filename) {
 			continue
 		}
 
-		// Record test files.
-		if strings.HasSuffix(file.Name(), "_test.go") {
-			if f, ok := defObj.(*types.Func); ok && strings.HasPrefix(f.Name(), "Test") && f.Name() != "TestMain" {
-				fa.testFuncs.Add(f)
-				uniqNames := util.MapGetOrCreate(fa.pkgTestUniqNames, strings.TrimSuffix(pkg.PkgPath, "_test"), func() util.Set[string] {
-					return util.NewSet[string]()
-				})
-				uniqNames.Add(f.Name())
+		var (
+			node      ast.Node
+			startLine = position.Line
+			endLine   = startLine
+		)
+		for _, tln := range nodes {
+			if tln.node.Pos() <= ident.Pos() && ident.End() <= tln.node.End() {
+				node = tln.node
+				startLine = sourcePositionOf(pkg.Fset, tln.start).Line
+				endLine = sourcePositionOf(pkg.Fset, tln.node.End()).Line
+				break
 			}
 		}
 
-		var node ast.Node
-		for _, d := range nodes {
-			if d.Pos() <= ident.Pos() && ident.End() <= d.End() {
-				node = d
-				break
+		if isRepeatable(defObj) {
+			fa.uniqObjNames[defObj] = types.ObjectString(defObj, nil) + " " + position.Filename + ":" + strconv.Itoa(position.Line)
+		}
+
+		fa.addDefinition(strings.TrimSuffix(pkg.PkgPath, "_test"), defObj, position.Filename, node, startLine, endLine)
+
+		if isLoadTimeCode(pkg.TypesInfo, defObj, node) {
+			fa.getDefinition(defObj).sideEffect = true
+		}
+
+		// Record test files. Tests from removed files can no longer be run.
+		if _, removed := fa.removedFiles[position.Filename]; !removed && strings.HasSuffix(position.Filename, "_test.go") {
+			if f, ok := defObj.(*types.Func); ok {
+				fa.recordTestFunc(f, runnableExamples)
 			}
 		}
+	}
+}
 
-		fa.addDefinition(defObj, file.Name(), node)
-		fa.addObj(strings.TrimSuffix(pkg.PkgPath, "_test"), file.Name(), defObj)
+func (fa *FileAnalyzer) recordFileHeader(fset *token.FileSet, astFile *ast.File) {
+	file := fset.File(astFile.Pos())
+	if file == nil {
+		return
 	}
+
+	// Header includes the package clause and every import declaration.
+	headerEnd := astFile.Name.End()
+	for _, d := range astFile.Decls {
+		if decl, ok := d.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			headerEnd = decl.End()
+		}
+	}
+	header := sourcePositionOf(fset, headerEnd)
+	fa.fileHeaderLines[header.Filename] = header.Line
 }
 
 func (fa *FileAnalyzer) analyzeUses(pkg *packages.Package) {
 	for ident, usedObj := range pkg.TypesInfo.Uses {
 		fa.addUsage(pkg.Fset, ident.Pos(), usedObj)
 	}
+
+	// Promoted fields and methods are reached through every embedded field in between.
+	for sel, selection := range pkg.TypesInfo.Selections {
+		t := selection.Recv()
+		index := selection.Index()
+		for _, i := range index[:len(index)-1] {
+			if ptr, ok := t.Underlying().(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			st, ok := t.Underlying().(*types.Struct)
+			if !ok {
+				break
+			}
+			field := st.Field(i)
+			fa.addUsage(pkg.Fset, sel.Sel.Pos(), field)
+			t = field.Type()
+		}
+	}
+
+	// Composite literals depend on every field of the struct, even the ones left out or unkeyed.
+	for _, astFile := range pkg.Syntax {
+		ast.Inspect(astFile, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			if named, ok := pkg.TypesInfo.TypeOf(lit).(*types.Named); ok {
+				if st, ok := named.Underlying().(*types.Struct); ok {
+					for i := 0; i < st.NumFields(); i++ {
+						fa.addUsage(pkg.Fset, lit.Pos(), st.Field(i))
+					}
+				}
+			}
+			return true
+		})
+	}
 }
 
 func (fa *FileAnalyzer) analyzeDefs(pkg *packages.Package) {
@@ -266,6 +694,43 @@ func (fa *FileAnalyzer) analyzeImplicits(pkg *packages.Package) {
 	}
 }
 
+// analyzeTestRunners finds tests running testify suites or Ginkgo specs, so that parts of them can be selected on
+// their own.
+func (fa *FileAnalyzer) analyzeTestRunners(pkg *packages.Package) {
+	for _, astFile := range pkg.Syntax {
+		for _, d := range astFile.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || decl.Body == nil {
+				continue
+			}
+			obj := pkg.TypesInfo.Defs[decl.Name]
+			if obj == nil {
+				continue
+			}
+			entryObjName := fa.objNameOf(obj)
+			if !fa.testFuncs.Has(entryObjName) {
+				continue
+			}
+
+			ast.Inspect(decl.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				switch {
+				case isSuiteRun(pkg.TypesInfo, call):
+					if suite := fa.newTestifySuite(pkg.TypesInfo.TypeOf(call.Args[1])); suite != nil {
+						fa.suites[entryObjName] = suite
+					}
+				case isGinkgoBootstrap(pkg.TypesInfo, call):
+					fa.ginkgoBootstraps.Add(entryObjName)
+				}
+				return true
+			})
+		}
+	}
+}
+
 func (fa *FileAnalyzer) addUsageToObjectType(fset *token.FileSet, usagePos token.Pos, obj types.Object) {
 	if obj == nil || obj.Pkg() == nil {
 		return
@@ -286,16 +751,23 @@ func (fa *FileAnalyzer) addUsage(fset *token.FileSet, usagePos token.Pos, usedOb
 		return
 	}
 
-	file := fset.File(usagePos)
+	fileName := sourcePositionOf(fset, usagePos).Filename
 
 	// Prevent object definitions from cache files.
-	if util.IsWithinPath(util.GoCacheFolder(), file.Name()) {
+	if util.IsWithinPath(util.GoCacheFolder(), fileName) {
 		return
 	}
 
 	// Prevent usages from outside the main package in question.
 	// Nil package indicate native objects.
-	if usedObj.Pkg() == nil || !util.IsSubPackage(fa.basePkg, usedObj.Pkg().Path()) {
+	if usedObj.Pkg() == nil || !fa.isBasePkg(usedObj.Pkg().Path()) {
+		return
+	}
+
+	if isCgoObject(fset, usedObj) {
+		for _, objName := range fa.userObjNamesAt(fileName, usagePos) {
+			fa.definitions[objName].usesCgo = true
+		}
 		return
 	}
 
@@ -305,14 +777,23 @@ func (fa *FileAnalyzer) addUsage(fset *token.FileSet, usagePos token.Pos, usedOb
 		return
 	}
 
-	usedObjName := types.ObjectString(usedObj, nil)
+	usedObjName := fa.objNameOf(usedObj)
 
-	for objName := range fa.fileObjNames[file.Name()] {
+	for _, objName := range fa.userObjNamesAt(fileName, usagePos) {
 		// Prevent self-usage.
 		if objName == usedObjName {
 			continue
 		}
+		fa.addEdge(objName, usedObjName)
+	}
+}
 
+// userObjNamesAt returns the object names of the definitions containing the position of the file, only keeping the
+// innermost subtest or Ginkgo node when within any.
+func (fa *FileAnalyzer) userObjNamesAt(fileName string, usagePos token.Pos) []string {
+	userObjNames := make([]string, 0)
+	innermostObjName := ""
+	for objName := range fa.fileObjNames[fileName] {
 		def := fa.definitions[objName]
 
 		// Ignore objects not within the user object.
@@ -320,46 +801,94 @@ func (fa *FileAnalyzer) addUsage(fset *token.FileSet, usagePos token.Pos, usedOb
 			continue
 		}
 
-		def.usingObjNames.Add(usedObjName)
-		usedDef.usedByObjNames.Add(objName)
+		if def.isNested() {
+			if innermostObjName == "" || fa.definitions[innermostObjName].node.Pos() < def.node.Pos() {
+				innermostObjName = objName
+			}
+			continue
+		}
+		userObjNames = append(userObjNames, objName)
 	}
+
+	// Usages within subtests or Ginkgo nodes only belong to the innermost one, so that only the subtests or specs
+	// using them are run.
+	if innermostObjName != "" {
+		return []string{innermostObjName}
+	}
+	return userObjNames
+}
+
+func (fa *FileAnalyzer) addEdge(userObjName, usedObjName string) {
+	fa.definitions[userObjName].usingObjNames.Add(usedObjName)
+	fa.definitions[usedObjName].usedByObjNames.Add(userObjName)
 }
 
 func (fa *FileAnalyzer) DetermineTests() (map[string]*TestedPackage, int) {
 	testedPkgs := make(map[string]*TestedPackage)
 	if fa.testAll {
 		for pkgPath := range fa.pkgDirs {
-			testedPkgs[pkgPath] = &TestedPackage{
-				Names:      util.NewSet("*"),
-				HasNotable: true,
-			}
+			testedPkg := getTestedPkg(testedPkgs, pkgPath)
+			testedPkg.Names = util.NewSet("*")
+			testedPkg.setAll(fa.testKindsOf(pkgPath))
+			testedPkg.HasNotable = true
+			testedPkg.Module = fa.moduleOf(pkgPath)
 		}
 		return testedPkgs, -1
 	}
 
-	fa.testsFromUsages(testedPkgs)
+	sideEffectPkgs := fa.testsFromUsages(testedPkgs, fa.queueUp)
+	fa.testsFromSideEffects(testedPkgs, sideEffectPkgs)
+	fa.testsFromChangedModules(testedPkgs)
+	fa.testsFromFuzzCorpora(testedPkgs)
+
+	for pkgPath, testedPkg := range testedPkgs {
+		testedPkg.Module = fa.moduleOf(pkgPath)
+	}
+
+	return testedPkgs, fa.consolidateTestedPkgs(testedPkgs)
+}
 
+// consolidateTestedPkgs returns the number of unique tests selected.
+func (fa *FileAnalyzer) consolidateTestedPkgs(testedPkgs map[string]*TestedPackage) int {
 	uniqueTestCount := 0
 
 	// Consolidate test packages that test everything.
 	for pkgPath, testedPkg := range testedPkgs {
-		uniqueTestCount += testedPkg.Names.Len()
-		if fa.pkgTestUniqNames != nil && testedPkg.Names.Len() == fa.pkgTestUniqNames[pkgPath].Len() {
-			testedPkgs[pkgPath].Names = util.NewSet("*")
+		for _, kind := range testKinds {
+			names := testedPkg.namesOf(kind)
+			count := fa.testCountOf(pkgPath, kind)
+			if names.Has("*") {
+				uniqueTestCount += count
+				continue
+			}
+			uniqueTestCount += names.Len()
+			// Filtered subtests need their parents to be run separately from the other tests.
+			if kind == testKindTest && len(testedPkg.Subtests) > 0 {
+				continue
+			}
+			if names.Len() > 0 && names.Len() == count {
+				*names = util.NewSet("*")
+			}
 		}
+
+		fa.consolidateSuites(pkgPath, testedPkg)
 	}
 
-	return testedPkgs, uniqueTestCount
+	return uniqueTestCount
 }
 
-func (fa *FileAnalyzer) testsFromUsages(testedPkgs map[string]*TestedPackage) {
+// testsFromUsages returns the object names of the load time code reached by the packages declaring them.
+func (fa *FileAnalyzer) testsFromUsages(testedPkgs map[string]*TestedPackage, queueUp func(addToQueue func(string))) map[string]string {
 	// Multi-source BFS.
 	queued := make(map[string]*traversal)
 	queue := make(traversalPQ, 0)
 
 	notablePkgs := util.NewSet[string]()
+	sideEffectPkgs := make(map[string]string)
+	fa.reachedFrom = make(map[string]string)
+	suiteMembers := fa.suiteMembers()
 
-	fa.queueUp(func(objName string) {
+	queueUp(func(objName string) {
 		if _, ok := queued[objName]; ok {
 			return
 		}
@@ -375,8 +904,7 @@ func (fa *FileAnalyzer) testsFromUsages(testedPkgs map[string]*TestedPackage) {
 		if def == nil {
 			return
 		}
-		pkg := strings.TrimSuffix(def.obj.Pkg().Path(), "_test")
-		notablePkgs.Add(pkg)
+		notablePkgs.Add(def.pkgPath)
 	})
 
 	for queue.Len() > 0 {
@@ -387,50 +915,251 @@ func (fa *FileAnalyzer) testsFromUsages(testedPkgs map[string]*TestedPackage) {
 			continue
 		}
 
-		if f, ok := def.obj.(*types.Func); ok && fa.testFuncs.Has(f) {
-			pkg := strings.TrimSuffix(f.Pkg().Path(), "_test")
-
-			testedPkg := util.MapGetOrCreate(testedPkgs, pkg, func() *TestedPackage {
-				return &TestedPackage{
-					Names:      util.NewSet[string](),
-					HasNotable: false,
-				}
-			})
-			testedPkg.Names.Add(f.Name())
-
-			if notablePkgs.Has(pkg) {
-				testedPkg.HasNotable = true
-			}
+		// Objects are popped with their most steps left, so their chains are the shortest ones.
+		if t.prev != nil {
+			fa.reachedFrom[t.objName] = t.prev.objName
+		} else {
+			fa.reachedFrom[t.objName] = ""
 		}
 
-		if t.stepsLeft <= 0 {
-			continue
+		if _, ok := sideEffectPkgs[def.pkgPath]; def.sideEffect && !ok {
+			sideEffectPkgs[def.pkgPath] = t.objName
 		}
-		nextStepsLeft := t.stepsLeft - 1
+		fa.selectReached(testedPkgs, t.objName, fa.usageChainOf(t.objName), suiteMembers, notablePkgs)
 
 		for userObjName := range def.usedByObjNames {
+			nextStepsLeft := t.stepsLeft - 1
+			if fa.isDispatch(userObjName, t.objName) {
+				nextStepsLeft = t.stepsLeft
+			}
+			if nextStepsLeft < 0 {
+				continue
+			}
+
 			nt, ok := queued[userObjName]
 			if !ok {
 				nt = &traversal{
 					objName:   userObjName,
 					stepsLeft: nextStepsLeft,
+					prev:      t,
 				}
 				heap.Push(&queue, nt)
 				queued[userObjName] = nt
 			} else if nt.stepsLeft < nextStepsLeft {
 				nt.stepsLeft = nextStepsLeft
+				nt.prev = t
 				heap.Fix(&queue, nt.index)
 			}
 		}
 	}
+
+	return sideEffectPkgs
+}
+
+// selectReached selects the tests run by reaching the object through the chain.
+func (fa *FileAnalyzer) selectReached(
+	testedPkgs map[string]*TestedPackage,
+	objName string,
+	chain []Hop,
+	suiteMembers map[string][]suiteMember,
+	notablePkgs util.Set[string],
+) {
+	def := fa.definitions[objName]
+
+	// TestMain wraps every test of the package.
+	if fa.testMainFuncs.Has(objName) && fa.pkgTestUniqNames[def.pkgPath].Len() > 0 {
+		testedPkg := getTestedPkg(testedPkgs, def.pkgPath)
+		testedPkg.setAll(fa.testKindsOf(def.pkgPath))
+		testedPkg.ByTestMain = true
+		testedPkg.addReason("*", chain)
+
+		if notablePkgs.Has(def.pkgPath) {
+			testedPkg.HasNotable = true
+		}
+	}
+
+	for _, member := range suiteMembers[objName] {
+		entryDef := fa.definitions[member.entryObjName]
+
+		testedPkg := getTestedPkg(testedPkgs, entryDef.pkgPath)
+		testedPkg.addSuiteMethod(entryDef.name, member.methodName)
+		testedPkg.addReason(entryDef.name, chain)
+
+		if notablePkgs.Has(entryDef.pkgPath) {
+			testedPkg.HasNotable = true
+		}
+	}
+
+	if def.ginkgoNode != nil {
+		testedPkg := getTestedPkg(testedPkgs, def.pkgPath)
+		for _, bootstrapObjName := range fa.ginkgoBootstrapsOf(def.pkgPath) {
+			testedPkg.addGinkgoNode(fa.definitions[bootstrapObjName].name, *def.ginkgoNode)
+			testedPkg.addReason(fa.definitions[bootstrapObjName].name, chain)
+		}
+
+		if notablePkgs.Has(def.pkgPath) {
+			testedPkg.HasNotable = true
+		}
+	}
+
+	if def.subtestOf != "" {
+		testedPkg := getTestedPkg(testedPkgs, def.pkgPath)
+		testedPkg.addSubtest(fa.definitions[def.subtestOf].name, def.name)
+		testedPkg.addReason(fa.definitions[def.subtestOf].name, chain)
+
+		if notablePkgs.Has(def.pkgPath) {
+			testedPkg.HasNotable = true
+		}
+	}
+
+	if fa.testFuncs.Has(objName) {
+		pkg := def.pkgPath
+
+		testedPkg := getTestedPkg(testedPkgs, pkg)
+		testedPkg.addName(def.name)
+		// Reaching the test itself runs its suite or specs as a whole.
+		delete(testedPkg.SuiteMethods, def.name)
+		delete(testedPkg.GinkgoNodes, def.name)
+		delete(testedPkg.Subtests, def.name)
+		testedPkg.addReason(def.name, chain)
+
+		if notablePkgs.Has(pkg) {
+			testedPkg.HasNotable = true
+		}
+	}
+}
+
+// usageChainOf returns the definitions the object has been reached through by the last search of tests, starting from
+// a notable object.
+func (fa *FileAnalyzer) usageChainOf(objName string) []Hop {
+	chain := make([]Hop, 0)
+	for {
+		def := fa.definitions[objName]
+		chain = append(chain, Hop{ObjName: objName, FileName: def.fileName, StartLine: def.startLine})
+
+		prevObjName := fa.reachedFrom[objName]
+		if prevObjName == "" {
+			break
+		}
+		objName = prevObjName
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+func getTestedPkg(testedPkgs map[string]*TestedPackage, pkgPath string) *TestedPackage {
+	return util.MapGetOrCreate(testedPkgs, pkgPath, func() *TestedPackage {
+		return &TestedPackage{
+			Names:        util.NewSet[string](),
+			Benchmarks:   util.NewSet[string](),
+			Fuzzes:       util.NewSet[string](),
+			Examples:     util.NewSet[string](),
+			SuiteMethods: make(map[string]util.Set[string]),
+			GinkgoNodes:  make(map[string]util.Set[GinkgoNode]),
+			Subtests:     make(map[string]util.Set[string]),
+			FuzzCorpora:  make(map[string]util.Set[string]),
+			Reasons:      make(map[string][]Hop),
+			HasNotable:   false,
+		}
+	})
+}
+
+func (fa *FileAnalyzer) testsFromChangedModules(testedPkgs map[string]*TestedPackage) {
+	if len(fa.changedModules) == 0 {
+		return
+	}
+
+	seedPkgPaths := util.NewSet[string]()
+	for pkgPath := range fa.pkgImports {
+		for _, modulePath := range fa.changedModules {
+			if isWithinModule(modulePath, pkgPath) {
+				seedPkgPaths.Add(pkgPath)
+			}
+		}
+	}
+
+	// Other dependencies importing the changed ones run their code as well, so depth only counts from the first
+	// package of the modules in question.
+	importers := fa.importersByPkg()
+	queue := seedPkgPaths.ToSlice()
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		for importerPkgPath := range importers[pkgPath] {
+			if fa.isBasePkg(importerPkgPath) || seedPkgPaths.Has(importerPkgPath) {
+				continue
+			}
+			seedPkgPaths.Add(importerPkgPath)
+			queue = append(queue, importerPkgPath)
+		}
+	}
+
+	for pkgPath := range fa.importersOf(seedPkgPaths, fa.depth) {
+		fa.selectAllTests(testedPkgs, pkgPath, nil)
+	}
+}
+
+// importersByPkg indexes the packages by the packages they import.
+func (fa *FileAnalyzer) importersByPkg() map[string]util.Set[string] {
+	importers := make(map[string]util.Set[string])
+	for pkgPath, imports := range fa.pkgImports {
+		for importPath := range imports {
+			importerPkgPaths := util.MapGetOrCreate(importers, importPath, func() util.Set[string] { return util.NewSet[string]() })
+			importerPkgPaths.Add(pkgPath)
+		}
+	}
+	return importers
+}
+
+// importersOf returns the packages importing any of the given packages, directly or through at most depth-1 other
+// packages.
+func (fa *FileAnalyzer) importersOf(pkgPaths util.Set[string], depth int) util.Set[string] {
+	importers := fa.importersByPkg()
+
+	reached := util.NewSet[string]()
+	frontier := pkgPaths.ToSlice()
+	for step := 0; step < depth && len(frontier) > 0; step++ {
+		next := make([]string, 0)
+		for _, pkgPath := range frontier {
+			for importerPkgPath := range importers[pkgPath] {
+				if reached.Has(importerPkgPath) || pkgPaths.Has(importerPkgPath) {
+					continue
+				}
+				reached.Add(importerPkgPath)
+				next = append(next, importerPkgPath)
+			}
+		}
+		frontier = next
+	}
+
+	return reached
+}
+
+// selectAllTests selects every test of the package, along with the usage chain leading to them if any.
+func (fa *FileAnalyzer) selectAllTests(testedPkgs map[string]*TestedPackage, pkgPath string, chain []Hop) {
+	if fa.pkgTestUniqNames[pkgPath].Len() == 0 {
+		return
+	}
+	testedPkg := getTestedPkg(testedPkgs, pkgPath)
+	testedPkg.setAll(fa.testKindsOf(pkgPath))
+	if chain != nil {
+		testedPkg.addReason("*", chain)
+	}
 }
 
 func (fa *FileAnalyzer) queueUp(addToQueue func(string)) {
+	embeddingObjNames := fa.embeddingObjNames()
 	for notableFileName := range fa.notableFileNames {
-		for objName := range fa.fileObjNames[notableFileName] {
+		fa.queueUpFile(notableFileName, addToQueue)
+
+		for objName := range embeddingObjNames[notableFileName] {
 			addToQueue(objName)
 		}
 
+		fa.queueUpTestdata(notableFileName, addToQueue)
+
+		fa.queueUpOtherFile(notableFileName, addToQueue)
+
 		// Add all that are related to misc usage.
 		for _, miscUsage := range fa.miscUsages {
 			if !miscUsage.Regexp.MatchString(notableFileName) {
@@ -481,33 +1210,136 @@ func (fa *FileAnalyzer) queueUp(addToQueue func(string)) {
 	}
 }
 
+func (fa *FileAnalyzer) queueUpFile(fileName string, addToQueue func(string)) {
+	if ranges, ok := fa.changedRanges(fileName); ok {
+		if objNames, ok := fa.objNamesWithinRanges(fileName, ranges); ok {
+			for _, objName := range objNames {
+				addToQueue(objName)
+			}
+			return
+		}
+	}
+
+	for objName := range fa.fileObjNames[fileName] {
+		addToQueue(objName)
+	}
+}
+
+func (fa *FileAnalyzer) changedRanges(fileName string) ([]NotableRange, bool) {
+	if baseSrc, ok := fa.baseFiles[fileName]; ok {
+		return semanticRanges(fileName, baseSrc)
+	}
+	ranges, ok := fa.notableRanges[fileName]
+	return ranges, ok
+}
+
+// objNamesWithinRanges returns false when any of the ranges cannot be attributed to a definition,
+// in which case the whole file should be considered.
+func (fa *FileAnalyzer) objNamesWithinRanges(fileName string, ranges []NotableRange) ([]string, bool) {
+	objNames := make([]string, 0)
+	for _, r := range ranges {
+		// Changing imports may change what every identifier in the file refers to.
+		if r.StartLine <= fa.fileHeaderLines[fileName] {
+			return nil, false
+		}
+
+		overlapping := make([]*definition, 0)
+		overlappingNames := make([]string, 0)
+		for objName := range fa.fileObjNames[fileName] {
+			def := fa.definitions[objName]
+			startLine, endLine := def.linesIn(fileName)
+			if r.EndLine < startLine || endLine < r.StartLine {
+				continue
+			}
+			overlapping = append(overlapping, def)
+			overlappingNames = append(overlappingNames, objName)
+		}
+		if len(overlapping) == 0 {
+			return nil, false
+		}
+
+		for i, def := range overlapping {
+			// Changes confined to fields do not concern the enclosing struct type as a whole.
+			if coveredByNested(fileName, def, r, overlapping) {
+				continue
+			}
+			objNames = append(objNames, overlappingNames[i])
+		}
+	}
+	return objNames, true
+}
+
+// coveredByNested reports whether every line of the range within the definition belongs to a definition nested in it.
+func coveredByNested(fileName string, outer *definition, r NotableRange, defs []*definition) bool {
+	outerStart, outerEnd := outer.linesIn(fileName)
+	for line := max(r.StartLine, outerStart); line <= min(r.EndLine, outerEnd); line++ {
+		covered := false
+		for _, inner := range defs {
+			innerStart, innerEnd := inner.linesIn(fileName)
+			isNested := outerStart <= innerStart && innerEnd <= outerEnd && innerEnd-innerStart < outerEnd-outerStart
+			if isNested && innerStart <= line && line <= innerEnd {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
 func (fa *FileAnalyzer) MarshalJSON() ([]byte, error) {
 	type jsonDefinition struct {
-		File   string           `json:"file"`
-		UsedBy util.Set[string] `json:"usedBy"`
-		Using  util.Set[string] `json:"using"`
+		File       string           `json:"file"`
+		UsedBy     util.Set[string] `json:"usedBy"`
+		Using      util.Set[string] `json:"using"`
+		SideEffect bool             `json:"sideEffect,omitempty"`
+		GinkgoNode *GinkgoNode      `json:"ginkgoNode,omitempty"`
+		SubtestOf  string           `json:"subtestOf,omitempty"`
+		EmbedFiles []string         `json:"embedFiles,omitempty"`
+		StringLits util.Set[string] `json:"stringLits,omitempty"`
+		UsesCgo    bool             `json:"usesCgo,omitempty"`
+		Profiles   util.Set[string] `json:"profiles,omitempty"`
 	}
 
 	type jsonAnalyzer struct {
-		TestFuncs   []string                    `json:"testFuncs"`
-		Definitions map[string]jsonDefinition   `json:"definitions"`
-		FileObjs    map[string]util.Set[string] `json:"fileObjs"`
+		TestFuncs         []string                    `json:"testFuncs"`
+		TestMainFuncs     []string                    `json:"testMainFuncs"`
+		RejectedTestFuncs map[string]string           `json:"rejectedTestFuncs"`
+		Suites            map[string]*testifySuite    `json:"suites"`
+		GinkgoBootstraps  []string                    `json:"ginkgoBootstraps"`
+		ReachedFrom       map[string]string           `json:"reachedFrom"`
+		Definitions       map[string]jsonDefinition   `json:"definitions"`
+		FileObjs          map[string]util.Set[string] `json:"fileObjs"`
 	}
 
 	x := jsonAnalyzer{
-		TestFuncs:   make([]string, 0, len(fa.testFuncs)),
-		Definitions: make(map[string]jsonDefinition, len(fa.definitions)),
-		FileObjs:    make(map[string]util.Set[string], len(fa.fileObjNames)),
+		TestFuncs:         make([]string, 0, len(fa.testFuncs)),
+		TestMainFuncs:     fa.testMainFuncs.ToSlice(),
+		RejectedTestFuncs: fa.rejectedTestFuncs,
+		Suites:            fa.suites,
+		GinkgoBootstraps:  fa.ginkgoBootstraps.ToSlice(),
+		ReachedFrom:       fa.reachedFrom,
+		Definitions:       make(map[string]jsonDefinition, len(fa.definitions)),
+		FileObjs:          make(map[string]util.Set[string], len(fa.fileObjNames)),
 	}
 	for testFunc := range fa.testFuncs {
-		x.TestFuncs = append(x.TestFuncs, testFunc.FullName())
+		x.TestFuncs = append(x.TestFuncs, testFunc)
 	}
 
 	for objName, def := range fa.definitions {
 		y := jsonDefinition{
-			File:   def.fileName,
-			UsedBy: util.NewSet[string](),
-			Using:  util.NewSet[string](),
+			File:       def.fileName,
+			UsedBy:     util.NewSet[string](),
+			Using:      util.NewSet[string](),
+			SideEffect: def.sideEffect,
+			GinkgoNode: def.ginkgoNode,
+			SubtestOf:  def.subtestOf,
+			EmbedFiles: def.embedFiles,
+			StringLits: def.stringLits,
+			UsesCgo:    def.usesCgo,
+			Profiles:   def.profiles,
 		}
 		for userObjName := range def.usedByObjNames {
 			y.UsedBy.Add(userObjName)
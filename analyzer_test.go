@@ -0,0 +1,252 @@
+package selectivetesting
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+const testModulePath = "example.com/m"
+
+// newTestModule writes the files into a new module in a temporary directory, returning the directory.
+func newTestModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"go.mod": "module " + testModulePath + "\n\ngo 1.21\n"})
+	writeTestFiles(t, dir, files)
+	return dir
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadTestModule loads the module in the directory with the notable files, given relative to it.
+func loadTestModule(t *testing.T, dir string, notableFileNames []string, options ...Option) *FileAnalyzer {
+	t.Helper()
+	absNotableFileNames := make([]string, 0, len(notableFileNames))
+	for _, name := range notableFileNames {
+		absNotableFileNames = append(absNotableFileNames, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+
+	fa := NewFileAnalyzer(testModulePath, absNotableFileNames, append([]Option{WithModuleDir(dir)}, options...)...)
+	if err := fa.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return fa
+}
+
+func TestChangedModulesThroughDependencies(t *testing.T) {
+	fa := NewFileAnalyzer(testModulePath, nil, WithDepth(1), WithChangedModules("github.com/davecgh/go-spew"))
+	fa.pkgImports = map[string]util.Set[string]{
+		testModulePath + "/a":                util.NewSet("github.com/stretchr/testify/assert"),
+		testModulePath + "/b":                util.NewSet(testModulePath + "/a"),
+		"github.com/stretchr/testify/assert": util.NewSet("github.com/davecgh/go-spew/spew"),
+		"github.com/davecgh/go-spew/spew":    util.NewSet[string](),
+	}
+	fa.pkgTestUniqNames = map[string]util.Set[string]{
+		testModulePath + "/a": util.NewSet("TestA"),
+		testModulePath + "/b": util.NewSet("TestB"),
+	}
+
+	testedPkgs := make(map[string]*TestedPackage)
+	fa.testsFromChangedModules(testedPkgs)
+
+	if testedPkg := testedPkgs[testModulePath+"/a"]; testedPkg == nil || !testedPkg.Has("TestA") {
+		t.Errorf("TestA is not selected")
+	}
+	// Importers within the module still count against depth.
+	if _, ok := testedPkgs[testModulePath+"/b"]; ok {
+		t.Errorf("tests of %s/b are selected beyond depth", testModulePath)
+	}
+}
+
+// definitionNamed returns the definition of the package with the name, failing the test when there is none.
+func definitionNamed(t *testing.T, fa *FileAnalyzer, pkgPath, name string) *definition {
+	t.Helper()
+	for _, def := range fa.definitions {
+		if def.pkgPath == pkgPath && def.name == name {
+			return def
+		}
+	}
+	t.Fatalf("no definition of %s in %s", name, pkgPath)
+	return nil
+}
+
+// assertSelected fails the test unless each of the names is selected in the package.
+func assertSelected(t *testing.T, testedPkgs map[string]*TestedPackage, pkgPath string, names ...string) {
+	t.Helper()
+	testedPkg := testedPkgs[pkgPath]
+	for _, name := range names {
+		if testedPkg == nil || !testedPkg.Has(name) {
+			t.Errorf("%s of %s is not selected", name, pkgPath)
+		}
+	}
+}
+
+// assertNotSelected fails the test when any of the names is selected in the package.
+func assertNotSelected(t *testing.T, testedPkgs map[string]*TestedPackage, pkgPath string, names ...string) {
+	t.Helper()
+	testedPkg := testedPkgs[pkgPath]
+	for _, name := range names {
+		if testedPkg != nil && testedPkg.Has(name) {
+			t.Errorf("%s of %s is selected", name, pkgPath)
+		}
+	}
+}
+
+func TestWorkspaceUsages(t *testing.T) {
+	// Workspaces reject -mod=mod, which may be set for the module of the analyzer itself.
+	t.Setenv("GOFLAGS", "")
+	const libModulePath = "example.com/lib"
+	dir := newTestModule(t, map[string]string{
+		"go.work":             "go 1.21\n\nuse (\n\t.\n\t./lib\n)\n",
+		"lib/go.mod":          "module " + libModulePath + "\n\ngo 1.21\n",
+		"lib/lib.go":          "package lib\n\nfunc Lib() int { return 1 }\n",
+		"lib/lib_test.go":     "package lib\n\nimport \"testing\"\n\nfunc TestLib(t *testing.T) { _ = Lib() }\n",
+		"a/a.go":              "package a\n",
+		"a/a_test.go":         "package a\n\nimport (\n\t\"testing\"\n\n\t\"example.com/lib\"\n)\n\nfunc TestA(t *testing.T) { _ = lib.Lib() }\n",
+		"unrelated/b.go":      "package unrelated\n",
+		"unrelated/b_test.go": "package unrelated\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+	})
+
+	fa := loadTestModule(t, dir, []string{"lib/lib.go"}, WithDepth(1),
+		WithBasePkgs(libModulePath), WithPatterns(testModulePath+"/...", libModulePath+"/..."))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestA")
+	assertSelected(t, testedPkgs, libModulePath, "TestLib")
+	assertNotSelected(t, testedPkgs, testModulePath+"/unrelated", "TestB")
+	if testedPkg := testedPkgs[testModulePath+"/a"]; testedPkg != nil && testedPkg.Module != testModulePath {
+		t.Errorf("module of %s/a is %q", testModulePath, testedPkg.Module)
+	}
+	if testedPkg := testedPkgs[libModulePath]; testedPkg != nil && testedPkg.Module != libModulePath {
+		t.Errorf("module of %s is %q", libModulePath, testedPkg.Module)
+	}
+}
+
+func TestFieldUsages(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go": "package a\n\ntype User struct {\n\tName string\n\tAge  int\n}\n",
+		"a/a_test.go": `package a
+
+import "testing"
+
+func TestName(t *testing.T) {
+	var u User
+	_ = u.Name
+}
+
+func TestAge(t *testing.T) {
+	var u User
+	u.Age = 1
+}
+
+func TestLiteral(t *testing.T) {
+	_ = User{Name: "a"}
+}
+`,
+	})
+
+	fa := loadTestModule(t, dir, nil, WithDepth(1), WithNotableRanges(NotableRange{
+		FileName:  filepath.Join(dir, "a", "a.go"),
+		StartLine: 5,
+		EndLine:   5,
+	}))
+	testedPkgs, _ := fa.DetermineTests()
+
+	// Composite literals depend on every field, even those left out.
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestAge", "TestLiteral")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "TestName")
+}
+
+func TestTestMainSelectsEveryTest(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"a/setup_test.go": `package a
+
+import (
+	"os"
+	"testing"
+)
+
+func setup() {}
+
+func TestMain(m *testing.M) {
+	setup()
+	os.Exit(m.Run())
+}
+`,
+		"a/a_test.go": `package a
+
+import "testing"
+
+func TestA(t *testing.T) { A() }
+
+func TestB(t *testing.T) {}
+
+func BenchmarkB(b *testing.B) {}
+`,
+	})
+
+	fa := loadTestModule(t, dir, nil, WithDepth(1), WithNotableRanges(NotableRange{
+		FileName:  filepath.Join(dir, "a", "setup_test.go"),
+		StartLine: 8,
+		EndLine:   8,
+	}))
+	testedPkgs, _ := fa.DetermineTests()
+
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg == nil || !testedPkg.ByTestMain {
+		t.Fatalf("tests are not selected by TestMain")
+	}
+	if !testedPkg.Names.Has("*") || !testedPkg.Benchmarks.Has("*") {
+		t.Errorf("got names %v and benchmarks %v, want every one of them", testedPkg.Names, testedPkg.Benchmarks)
+	}
+	if _, ok := testedPkg.Reasons["*"]; !ok {
+		t.Errorf("no reason is given for selecting every test")
+	}
+}
+
+func TestReasonsFollowUsageChain(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":      "package a\n\nfunc A() int { return 1 }\n",
+		"b/b.go":      "package b\n\nimport \"example.com/m/a\"\n\nfunc B() int { return a.A() }\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) { B() }\n",
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(2))
+	testedPkgs, _ := fa.DetermineTests()
+
+	testedPkg := testedPkgs[testModulePath+"/b"]
+	if testedPkg == nil {
+		t.Fatalf("tests of %s/b are not selected", testModulePath)
+	}
+	wantObjNames := make([]string, 0, 3)
+	for _, def := range []*definition{
+		definitionNamed(t, fa, testModulePath+"/a", "A"),
+		definitionNamed(t, fa, testModulePath+"/b", "B"),
+		definitionNamed(t, fa, testModulePath+"/b", "TestB"),
+	} {
+		wantObjNames = append(wantObjNames, fa.objNameOf(def.obj))
+	}
+	objNames := make([]string, 0, len(testedPkg.Reasons["TestB"]))
+	for _, hop := range testedPkg.Reasons["TestB"] {
+		objNames = append(objNames, hop.ObjName)
+	}
+	if !slices.Equal(objNames, wantObjNames) {
+		t.Errorf("got chain %q, want %q", objNames, wantObjNames)
+	}
+}
@@ -0,0 +1,33 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+type topLevelNode struct {
+	node  ast.Node
+	start token.Pos
+}
+
+// stringLitArgOf returns the value of the first argument of the call when it is a string literal, such as the name of
+// a subtest or the text of a Ginkgo node.
+func stringLitArgOf(call *ast.CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+func docStart(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return pos
+}
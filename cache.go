@@ -0,0 +1,426 @@
+package selectivetesting
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+// Bump whenever the cached graph changes in shape or meaning.
+const cacheVersion = 18
+
+type cachedPackage struct {
+	Hash    string
+	Imports []string
+}
+
+type cachedDefinition struct {
+	PkgPath    string
+	Name       string
+	FileName   string
+	StartLine  int
+	EndLine    int
+	UsedBy     []string
+	Using      []string
+	SideEffect bool
+	GinkgoNode *GinkgoNode
+	SubtestOf  string
+	EmbedFiles []string
+	StringLits []string
+	UsesCgo    bool
+	Profiles   []string
+	Locations  []definitionLocation
+}
+
+type graphCache struct {
+	Version           int
+	Packages          map[string]cachedPackage
+	Definitions       map[string]cachedDefinition
+	TestFuncs         []string
+	TestMainFuncs     []string
+	RejectedTestFuncs map[string]string
+	Suites            map[string]*testifySuite
+	GinkgoBootstraps  []string
+	FileHeaderLines   map[string]int
+	PkgImports        map[string][]string
+	Dispatches        map[string][]string
+}
+
+func (fa *FileAnalyzer) loadWithCache() error {
+	pkgHashes, err := fa.hashPackages()
+	if err != nil {
+		return err
+	}
+
+	cache, err := readCache(fa.cachePath)
+	if err != nil {
+		return err
+	}
+
+	dirtyPkgPaths := dirtyPackages(cache, pkgHashes)
+	if cache != nil {
+		fa.restoreCache(cache, pkgHashes, dirtyPkgPaths)
+	}
+
+	if dirtyPkgPaths.Len() > 0 {
+		if err := fa.loadProfiles(dirtyPkgPaths.ToSlice()...); err != nil {
+			return err
+		}
+	}
+
+	fa.relinkEdges()
+
+	return fa.writeCache(pkgHashes)
+}
+
+// hashPackages lists the packages without type-checking them and computes a hash of everything that could change
+// their part of the graph.
+func (fa *FileAnalyzer) hashPackages() (map[string]cachedPackage, error) {
+	// Packages are hashed by their files under every build profile.
+	pkgs := make([]*packages.Package, 0)
+	for _, profile := range fa.buildProfiles() {
+		profilePkgs, err := packages.Load(&packages.Config{
+			Dir:        fa.moduleDir,
+			Mode:       packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedModule | packages.NeedEmbedFiles,
+			BuildFlags: fa.BuildFlagsOf(profile),
+			Env:        envOf(profile),
+			Tests:      true,
+			Overlay:    fa.removedFiles,
+		}, fa.patterns...)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, profilePkgs...)
+	}
+
+	commonHash := sha256.New()
+	io.WriteString(commonHash, strings.Join(fa.basePkgs, "\x00"))
+	io.WriteString(commonHash, strings.Join(fa.buildFlags, "\x00"))
+	for _, profile := range fa.profiles {
+		io.WriteString(commonHash, profile.Name+"\x00"+strings.Join(profile.BuildFlags, "\x00")+"\x00"+strings.Join(profile.Env, "\x00"))
+	}
+	for _, fileName := range []string{"go.mod", "go.sum", "go.work", "go.work.sum"} {
+		if err := fa.hashFile(commonHash, filepath.Join(fa.moduleDir, fileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	commonSum := commonHash.Sum(nil)
+
+	pkgFileNames := make(map[string]util.Set[string])
+	pkgEmbedFileNames := make(map[string]util.Set[string])
+	pkgImports := make(map[string]util.Set[string])
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue
+		}
+		fa.addPkgPath(pkg)
+		fa.addOtherFiles(pkg)
+
+		pkgPath := strings.TrimSuffix(pkg.PkgPath, "_test")
+		fileNames := util.MapGetOrCreate(pkgFileNames, pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+		fileNames.Add(pkg.GoFiles...)
+		fileNames.Add(pkg.OtherFiles...)
+		if pkg.Module != nil && pkg.Module.GoMod != "" {
+			fileNames.Add(pkg.Module.GoMod)
+		}
+
+		// Only the names of embedded files matter, their contents are never analyzed.
+		embedFileNames := util.MapGetOrCreate(pkgEmbedFileNames, pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+		embedFileNames.Add(pkg.EmbedFiles...)
+
+		imports := util.MapGetOrCreate(pkgImports, pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+		for importPath := range pkg.Imports {
+			imports.Add(importPath)
+		}
+	}
+
+	pkgHashes := make(map[string]cachedPackage, len(pkgFileNames))
+	for pkgPath, fileNames := range pkgFileNames {
+		sortedFileNames := fileNames.ToSlice()
+		sort.Strings(sortedFileNames)
+
+		h := sha256.New()
+		h.Write(commonSum)
+		for _, fileName := range sortedFileNames {
+			io.WriteString(h, fileName)
+			if err := fa.hashFile(h, fileName); err != nil {
+				return nil, err
+			}
+		}
+
+		embedFileNames := pkgEmbedFileNames[pkgPath].ToSlice()
+		sort.Strings(embedFileNames)
+		io.WriteString(h, strings.Join(embedFileNames, "\x00"))
+
+		imports := pkgImports[pkgPath].ToSlice()
+		sort.Strings(imports)
+
+		pkgHashes[pkgPath] = cachedPackage{
+			Hash:    hex.EncodeToString(h.Sum(nil)),
+			Imports: imports,
+		}
+	}
+
+	return pkgHashes, nil
+}
+
+func (fa *FileAnalyzer) hashFile(w io.Writer, fileName string) error {
+	if content, ok := fa.removedFiles[fileName]; ok {
+		_, err := w.Write(content)
+		return err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// dirtyPackages returns packages that have changed since the cache was written, along with everything importing them
+// and the packages implementing their interfaces.
+func dirtyPackages(cache *graphCache, pkgHashes map[string]cachedPackage) util.Set[string] {
+	importers := make(map[string]util.Set[string])
+	dirtyPkgPaths := util.NewSet[string]()
+
+	// Dispatches are only found from the concrete types of the packages being loaded, which need not import the
+	// interfaces they implement.
+	implementers := make(map[string]util.Set[string])
+	if cache != nil {
+		for ifaceMethodObjName, methodObjNames := range cache.Dispatches {
+			ifacePkgPath := cache.Definitions[ifaceMethodObjName].PkgPath
+			implementerPkgPaths := util.MapGetOrCreate(implementers, ifacePkgPath, func() util.Set[string] { return util.NewSet[string]() })
+			for _, methodObjName := range methodObjNames {
+				implementerPkgPaths.Add(cache.Definitions[methodObjName].PkgPath)
+			}
+		}
+	}
+
+	for pkgPath, pkgHash := range pkgHashes {
+		for _, importPath := range pkgHash.Imports {
+			importerPkgPaths := util.MapGetOrCreate(importers, importPath, func() util.Set[string] { return util.NewSet[string]() })
+			importerPkgPaths.Add(pkgPath)
+		}
+
+		if cache == nil || cache.Packages[pkgPath].Hash != pkgHash.Hash {
+			dirtyPkgPaths.Add(pkgPath)
+		}
+	}
+
+	queue := dirtyPkgPaths.ToSlice()
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+
+		for _, affectedPkgPaths := range []util.Set[string]{importers[pkgPath], implementers[pkgPath]} {
+			for affectedPkgPath := range affectedPkgPaths {
+				// Packages that no longer exist have nothing to reload.
+				if _, ok := pkgHashes[affectedPkgPath]; !ok || dirtyPkgPaths.Has(affectedPkgPath) {
+					continue
+				}
+				dirtyPkgPaths.Add(affectedPkgPath)
+				queue = append(queue, affectedPkgPath)
+			}
+		}
+	}
+
+	return dirtyPkgPaths
+}
+
+func readCache(cachePath string) (*graphCache, error) {
+	file, err := os.Open(cachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cache graphCache
+	// A broken or outdated cache is as good as no cache.
+	if err := gob.NewDecoder(file).Decode(&cache); err != nil || cache.Version != cacheVersion {
+		return nil, nil
+	}
+	return &cache, nil
+}
+
+func (fa *FileAnalyzer) restoreCache(cache *graphCache, pkgHashes map[string]cachedPackage, dirtyPkgPaths util.Set[string]) {
+	isKept := func(pkgPath string) bool {
+		_, ok := pkgHashes[pkgPath]
+		return ok && !dirtyPkgPaths.Has(pkgPath)
+	}
+
+	for objName, cd := range cache.Definitions {
+		if !isKept(cd.PkgPath) {
+			continue
+		}
+		fa.putDefinition(objName, &definition{
+			pkgPath:        cd.PkgPath,
+			name:           cd.Name,
+			fileName:       cd.FileName,
+			startLine:      cd.StartLine,
+			endLine:        cd.EndLine,
+			usedByObjNames: util.SetFrom(cd.UsedBy),
+			usingObjNames:  util.SetFrom(cd.Using),
+			sideEffect:     cd.SideEffect,
+			ginkgoNode:     cd.GinkgoNode,
+			subtestOf:      cd.SubtestOf,
+			embedFiles:     cd.EmbedFiles,
+			stringLits:     util.SetFrom(cd.StringLits),
+			usesCgo:        cd.UsesCgo,
+			profiles:       util.SetFrom(cd.Profiles),
+			otherLocations: cd.Locations,
+		})
+	}
+
+	// Usages from the dirty packages will be recreated once they are analyzed again.
+	for _, def := range fa.definitions {
+		for objName := range def.usedByObjNames {
+			if _, ok := fa.definitions[objName]; !ok {
+				def.usedByObjNames.Delete(objName)
+			}
+		}
+	}
+
+	for _, objName := range cache.TestFuncs {
+		if _, ok := fa.definitions[objName]; ok {
+			fa.addTestFunc(objName)
+		}
+	}
+
+	for _, objName := range cache.TestMainFuncs {
+		if _, ok := fa.definitions[objName]; ok {
+			fa.testMainFuncs.Add(objName)
+		}
+	}
+
+	for objName, reason := range cache.RejectedTestFuncs {
+		if _, ok := fa.definitions[objName]; ok {
+			fa.rejectedTestFuncs[objName] = reason
+		}
+	}
+
+	for objName, suite := range cache.Suites {
+		if _, ok := fa.definitions[objName]; ok {
+			fa.suites[objName] = suite
+		}
+	}
+
+	for _, objName := range cache.GinkgoBootstraps {
+		if _, ok := fa.definitions[objName]; ok {
+			fa.ginkgoBootstraps.Add(objName)
+		}
+	}
+
+	for fileName, line := range cache.FileHeaderLines {
+		fa.fileHeaderLines[fileName] = line
+	}
+
+	// Edges of the dispatches are restored along with the interface methods, to be relinked to reanalyzed methods.
+	for ifaceMethodObjName, methodObjNames := range cache.Dispatches {
+		if _, ok := fa.definitions[ifaceMethodObjName]; ok {
+			fa.dispatches[ifaceMethodObjName] = util.SetFrom(methodObjNames)
+		}
+	}
+
+	// Includes packages outside of the module, which are only reloaded along with the dirty packages.
+	for pkgPath, imports := range cache.PkgImports {
+		if !dirtyPkgPaths.Has(pkgPath) {
+			fa.pkgImports[pkgPath] = util.SetFrom(imports)
+		}
+	}
+}
+
+// relinkEdges restores edges from restored definitions to reanalyzed ones, such as dispatches to implementations in
+// packages that do not import the interface, and drops those to definitions that no longer exist.
+func (fa *FileAnalyzer) relinkEdges() {
+	for objName, def := range fa.definitions {
+		for usedObjName := range def.usingObjNames {
+			usedDef, ok := fa.definitions[usedObjName]
+			if !ok {
+				def.usingObjNames.Delete(usedObjName)
+				continue
+			}
+			usedDef.usedByObjNames.Add(objName)
+		}
+	}
+}
+
+func (fa *FileAnalyzer) writeCache(pkgHashes map[string]cachedPackage) error {
+	cache := graphCache{
+		Version:           cacheVersion,
+		Packages:          pkgHashes,
+		Definitions:       make(map[string]cachedDefinition, len(fa.definitions)),
+		TestFuncs:         fa.testFuncs.ToSlice(),
+		TestMainFuncs:     fa.testMainFuncs.ToSlice(),
+		RejectedTestFuncs: fa.rejectedTestFuncs,
+		Suites:            fa.suites,
+		GinkgoBootstraps:  fa.ginkgoBootstraps.ToSlice(),
+		FileHeaderLines:   fa.fileHeaderLines,
+		PkgImports:        make(map[string][]string, len(fa.pkgImports)),
+		Dispatches:        make(map[string][]string, len(fa.dispatches)),
+	}
+	for pkgPath, imports := range fa.pkgImports {
+		cache.PkgImports[pkgPath] = imports.ToSlice()
+	}
+	for ifaceMethodObjName, methodObjNames := range fa.dispatches {
+		if _, ok := fa.definitions[ifaceMethodObjName]; !ok {
+			continue
+		}
+		existing := make([]string, 0, methodObjNames.Len())
+		for methodObjName := range methodObjNames {
+			if _, ok := fa.definitions[methodObjName]; ok {
+				existing = append(existing, methodObjName)
+			}
+		}
+		cache.Dispatches[ifaceMethodObjName] = existing
+	}
+	for objName, def := range fa.definitions {
+		cache.Definitions[objName] = cachedDefinition{
+			PkgPath:    def.pkgPath,
+			Name:       def.name,
+			FileName:   def.fileName,
+			StartLine:  def.startLine,
+			EndLine:    def.endLine,
+			UsedBy:     def.usedByObjNames.ToSlice(),
+			Using:      def.usingObjNames.ToSlice(),
+			SideEffect: def.sideEffect,
+			GinkgoNode: def.ginkgoNode,
+			SubtestOf:  def.subtestOf,
+			EmbedFiles: def.embedFiles,
+			StringLits: def.stringLits.ToSlice(),
+			UsesCgo:    def.usesCgo,
+			Profiles:   def.profiles.ToSlice(),
+			Locations:  def.otherLocations,
+		}
+	}
+
+	// Write to a temporary file first so that an interrupted run never leaves a truncated cache.
+	tmpFile, err := os.CreateTemp(filepath.Dir(fa.cachePath), filepath.Base(fa.cachePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := gob.NewEncoder(tmpFile).Encode(cache); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), fa.cachePath)
+}
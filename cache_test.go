@@ -0,0 +1,62 @@
+package selectivetesting
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheKeepsDispatchesToCleanPackages(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"iface/iface.go": "package iface\n\ntype Doer interface{ Do() int }\n\nfunc Call(d Doer) int { return d.Do() }\n",
+		// The implementation only satisfies the interface structurally.
+		"impl/impl.go":         "package impl\n\ntype T struct{}\n\nfunc (T) Do() int { return 1 }\n",
+		"app/app_test.go":      "package app\n\nimport (\n\t\"testing\"\n\n\t\"example.com/m/iface\"\n\t\"example.com/m/impl\"\n)\n\nfunc TestDo(t *testing.T) { _ = iface.Call(impl.T{}) }\n",
+		"app/app.go":           "package app\n",
+		"iface/iface_extra.go": "package iface\n",
+	})
+	cachePath := filepath.Join(t.TempDir(), "graph.cache")
+
+	loadTestModule(t, dir, nil, WithDepth(5), WithCachePath(cachePath))
+
+	// Only the package of the interface is reloaded from here on.
+	writeTestFiles(t, dir, map[string]string{"iface/iface_extra.go": "package iface\n\nconst X = 1\n"})
+
+	for i := 0; i < 2; i++ {
+		fa := loadTestModule(t, dir, []string{"impl/impl.go"}, WithDepth(5), WithCachePath(cachePath), WithNotableRanges(NotableRange{
+			FileName:  filepath.Join(dir, "impl", "impl.go"),
+			StartLine: 5,
+			EndLine:   5,
+		}))
+		testedPkgs, _ := fa.DetermineTests()
+		if testedPkg := testedPkgs[testModulePath+"/app"]; testedPkg == nil || !testedPkg.Has("TestDo") {
+			t.Fatalf("run %d: TestDo is not selected", i+1)
+		}
+	}
+}
+
+func TestCacheReloadsChangedPackages(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":      "package a\n\nfunc A() int { return 1 }\n",
+		"b/b.go":      "package b\n\nfunc B() int { return 0 }\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) { _ = B() }\n",
+	})
+	cachePath := filepath.Join(t.TempDir(), "graph.cache")
+
+	loadTestModule(t, dir, nil, WithDepth(2), WithCachePath(cachePath))
+
+	writeTestFiles(t, dir, map[string]string{
+		"b/b.go": "package b\n\nimport \"example.com/m/a\"\n\nfunc B() int { return a.A() }\n",
+	})
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(2), WithCachePath(cachePath))
+
+	// Definitions restored from the cache have no objects.
+	if definitionNamed(t, fa, testModulePath+"/a", "A").obj != nil {
+		t.Errorf("unchanged package a is loaded again")
+	}
+	if definitionNamed(t, fa, testModulePath+"/b", "B").obj == nil {
+		t.Errorf("changed package b is restored from the cache")
+	}
+
+	testedPkgs, _ := fa.DetermineTests()
+	assertSelected(t, testedPkgs, testModulePath+"/b", "TestB")
+}
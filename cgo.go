@@ -0,0 +1,72 @@
+package selectivetesting
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+// sourcePositionOf returns the position of the source file, which for files generated by cgo into the build cache is
+// the one given by their line directives.
+func sourcePositionOf(fset *token.FileSet, pos token.Pos) token.Position {
+	position := fset.PositionFor(pos, false)
+	if util.IsWithinPath(util.GoCacheFolder(), position.Filename) {
+		return fset.PositionFor(pos, true)
+	}
+	return position
+}
+
+// isCgoObject reports whether the object is generated by cgo, such as the C functions and types used by the package,
+// which are declared in cache files without any source.
+func isCgoObject(fset *token.FileSet, obj types.Object) bool {
+	return obj.Pos().IsValid() && util.IsWithinPath(util.GoCacheFolder(), sourcePositionOf(fset, obj.Pos()).Filename)
+}
+
+// addOtherFiles records the files of the package that are not compiled as Go, such as C sources and headers, along
+// with those excluded by build constraints.
+func (fa *FileAnalyzer) addOtherFiles(pkg *packages.Package) {
+	if strings.HasSuffix(pkg.PkgPath, ".test]") || strings.HasSuffix(pkg.PkgPath, ".test") {
+		return
+	}
+	pkgPath := strings.TrimSuffix(pkg.PkgPath, "_test")
+
+	for _, fileName := range pkg.OtherFiles {
+		fa.otherFilePkgs[fileName] = pkgPath
+	}
+	for _, fileName := range pkg.IgnoredFiles {
+		fa.otherFilePkgs[fileName] = pkgPath
+	}
+}
+
+// queueUpOtherFile queues up the cgo-using definitions of the package owning the non-Go file, or all of its objects
+// when none uses cgo or the file is a Go file excluded by build constraints.
+func (fa *FileAnalyzer) queueUpOtherFile(fileName string, addToQueue func(string)) {
+	pkgPath, ok := fa.otherFilePkgs[fileName]
+	if !ok {
+		return
+	}
+	// Files excluded under one build profile may be compiled under another.
+	if _, compiled := fa.fileHeaderLines[fileName]; compiled {
+		return
+	}
+
+	if !strings.HasSuffix(fileName, ".go") {
+		found := false
+		for objName := range fa.pkgObjNames[pkgPath] {
+			if fa.definitions[objName].usesCgo {
+				addToQueue(objName)
+				found = true
+			}
+		}
+		if found {
+			return
+		}
+	}
+
+	for objName := range fa.pkgObjNames[pkgPath] {
+		addToQueue(objName)
+	}
+}
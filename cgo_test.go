@@ -0,0 +1,41 @@
+package selectivetesting
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestCgoSourceSelectsCgoUsers(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("cgo needs a C compiler")
+	}
+	t.Setenv("CGO_ENABLED", "1")
+
+	dir := newTestModule(t, map[string]string{
+		"a/add.h": "int add(int a, int b);\n",
+		"a/add.c": "#include \"add.h\"\n\nint add(int a, int b) { return a + b; }\n",
+		"a/a.go": `package a
+
+// #include "add.h"
+import "C"
+
+func Add(a, b int) int { return int(C.add(C.int(a), C.int(b))) }
+
+func Sub(a, b int) int { return a - b }
+`,
+		"a/a_test.go": `package a
+
+import "testing"
+
+func TestAdd(t *testing.T) { Add(1, 2) }
+
+func TestSub(t *testing.T) { Sub(2, 1) }
+`,
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/add.c"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestAdd")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "TestSub")
+}
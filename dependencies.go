@@ -0,0 +1,111 @@
+package selectivetesting
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Dependency is a definition reached from a test through the objects it uses.
+type Dependency struct {
+	ObjName   string
+	PkgPath   string
+	FileName  string
+	StartLine int
+	// Number of usages between the test and the definition.
+	Steps int
+}
+
+// Dependencies returns the definitions used by the test directly or through at most depth-1 other definitions, or
+// through any number of them when depth is not positive. The test is named by its package path and name, such as
+// example.com/pkg.TestFoo, or by its name alone when no other package has a test with the same name.
+//
+// The dependencies are sorted by package path, file name and line.
+func (fa *FileAnalyzer) Dependencies(testName string, depth int) ([]Dependency, error) {
+	testObjName, err := fa.testObjNameOf(testName)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSteps := depth
+	if depth <= 0 {
+		maxSteps = math.MaxInt
+	}
+
+	// Usages within the subtests, suites and specs run by the test are attributed to them instead.
+	nodeObjNames := fa.nodesRunBy(testObjName)
+	deps := make([]Dependency, 0)
+	for objName, steps := range fa.stepsToUsed(nodeObjNames, maxSteps) {
+		if slices.Contains(nodeObjNames, objName) {
+			continue
+		}
+		def := fa.definitions[objName]
+		deps = append(deps, Dependency{
+			ObjName:   objName,
+			PkgPath:   def.pkgPath,
+			FileName:  def.fileName,
+			StartLine: def.startLine,
+			Steps:     steps,
+		})
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].PkgPath != deps[j].PkgPath {
+			return deps[i].PkgPath < deps[j].PkgPath
+		}
+		if deps[i].FileName != deps[j].FileName {
+			return deps[i].FileName < deps[j].FileName
+		}
+		if deps[i].StartLine != deps[j].StartLine {
+			return deps[i].StartLine < deps[j].StartLine
+		}
+		return deps[i].ObjName < deps[j].ObjName
+	})
+	return deps, nil
+}
+
+func (fa *FileAnalyzer) testObjNameOf(testName string) (string, error) {
+	pkgPath, name := "", testName
+	if i := strings.LastIndex(testName, "."); i >= 0 {
+		pkgPath, name = testName[:i], testName[i+1:]
+	}
+
+	objNames := make([]string, 0, 1)
+	for objName := range fa.testFuncs {
+		def := fa.definitions[objName]
+		if def.name == name && (pkgPath == "" || def.pkgPath == pkgPath) {
+			objNames = append(objNames, objName)
+		}
+	}
+	switch len(objNames) {
+	case 0:
+		return "", fmt.Errorf("test %s not found", testName)
+	case 1:
+		return objNames[0], nil
+	}
+	return "", fmt.Errorf("test %s is ambiguous, qualify it with its package path", testName)
+}
+
+// nodesRunBy returns the object names of the test along with the subtests, suite methods and Ginkgo nodes it runs.
+func (fa *FileAnalyzer) nodesRunBy(testObjName string) []string {
+	objNames := []string{testObjName}
+	testDef := fa.definitions[testObjName]
+	isBootstrap := fa.ginkgoBootstraps.Has(testObjName)
+
+	for objName := range fa.pkgObjNames[testDef.pkgPath] {
+		def := fa.definitions[objName]
+		if def.subtestOf == testObjName || (isBootstrap && def.ginkgoNode != nil) {
+			objNames = append(objNames, objName)
+		}
+	}
+
+	if suite, ok := fa.suites[testObjName]; ok {
+		for objName := range suite.Methods {
+			objNames = append(objNames, objName)
+		}
+		objNames = append(objNames, suite.Lifecycles...)
+	}
+	return objNames
+}
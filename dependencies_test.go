@@ -0,0 +1,41 @@
+package selectivetesting
+
+import "testing"
+
+func TestDependencies(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":      "package a\n\nfunc A() int { return 1 }\n",
+		"b/b.go":      "package b\n\nimport \"example.com/m/a\"\n\nfunc B() int { return a.A() }\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) { B() }\n",
+		"c/c_test.go": "package c\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+	})
+	fa := loadTestModule(t, dir, nil)
+
+	if _, err := fa.Dependencies("TestB", 0); err == nil {
+		t.Errorf("expected an error for a test name shared by several packages")
+	}
+
+	for _, tc := range []struct {
+		depth     int
+		wantNames []string
+		wantSteps []int
+	}{
+		{depth: 0, wantNames: []string{"A", "B"}, wantSteps: []int{2, 1}},
+		{depth: 1, wantNames: []string{"B"}, wantSteps: []int{1}},
+	} {
+		deps, err := fa.Dependencies(testModulePath+"/b.TestB", tc.depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deps) != len(tc.wantNames) {
+			t.Errorf("got %d dependencies at depth %d, want %d", len(deps), tc.depth, len(tc.wantNames))
+			continue
+		}
+		for i, dep := range deps {
+			if name := fa.definitions[dep.ObjName].name; name != tc.wantNames[i] || dep.Steps != tc.wantSteps[i] {
+				t.Errorf("got %s at %d steps at depth %d, want %s at %d steps",
+					name, dep.Steps, tc.depth, tc.wantNames[i], tc.wantSteps[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package selectivetesting
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseUnifiedDiff reads a unified diff and returns the affected files along with the changed line ranges
+// of the new revision. Paths are joined with baseDir.
+func ParseUnifiedDiff(r io.Reader, baseDir string) ([]string, []NotableRange, error) {
+	var (
+		fileNames = make([]string, 0)
+		ranges    = make([]NotableRange, 0)
+
+		oldFileName string
+		newFileName string
+		newLine     int
+		oldLeft     int
+		newLeft     int
+		changed     []int
+	)
+
+	flush := func() {
+		if newFileName != "" {
+			ranges = append(ranges, linesToRanges(newFileName, changed)...)
+		}
+		changed = changed[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case oldLeft > 0 || newLeft > 0:
+			// Inside of a hunk.
+
+		case strings.HasPrefix(line, "diff "):
+			flush()
+			oldFileName, newFileName = "", ""
+			continue
+
+		case strings.HasPrefix(line, "--- "):
+			flush()
+			oldFileName = diffPath(baseDir, line[4:], "a/")
+			continue
+
+		case strings.HasPrefix(line, "+++ "):
+			newFileName = diffPath(baseDir, line[4:], "b/")
+			if oldFileName != "" {
+				fileNames = append(fileNames, oldFileName)
+			}
+			if newFileName != "" && newFileName != oldFileName {
+				fileNames = append(fileNames, newFileName)
+			}
+			continue
+
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			newLine, oldLeft, newLeft, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+
+		default:
+			continue
+		}
+
+		if line == "" {
+			// Some tools strip the trailing space of empty context lines.
+			line = " "
+		}
+
+		switch line[0] {
+		case ' ':
+			newLine++
+			oldLeft--
+			newLeft--
+		case '+':
+			changed = append(changed, newLine)
+			newLine++
+			newLeft--
+		case '-':
+			// A removal sits between two lines of the new revision.
+			if newLine > 1 {
+				changed = append(changed, newLine-1)
+			}
+			changed = append(changed, newLine)
+			oldLeft--
+		case '\\':
+			// No newline at end of file.
+		default:
+			oldLeft, newLeft = 0, 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	flush()
+
+	return fileNames, ranges, nil
+}
+
+func diffPath(baseDir, header, prefix string) string {
+	// Some tools append a timestamp after a tab.
+	header, _, _ = strings.Cut(header, "\t")
+	if header == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(header); err == nil {
+		header = unquoted
+	}
+	return filepath.Join(baseDir, strings.TrimPrefix(header, prefix))
+}
+
+func parseHunkHeader(line string) (newStart, oldCount, newCount int, err error) {
+	// @@ -l,s +l,s @@ optional section heading
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	if _, oldCount, err = parseHunkRange(fields[1][1:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	if newStart, newCount, err = parseHunkRange(fields[2][1:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+
+	// An empty range starts right after the line it refers to.
+	if newCount == 0 {
+		newStart++
+	}
+	return newStart, oldCount, newCount, nil
+}
+
+func parseHunkRange(s string) (int, int, error) {
+	startStr, countStr, ok := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if ok {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+func linesToRanges(fileName string, lines []int) []NotableRange {
+	ranges := make([]NotableRange, 0)
+	for _, line := range lines {
+		if n := len(ranges); n > 0 && line <= ranges[n-1].EndLine+1 {
+			if line > ranges[n-1].EndLine {
+				ranges[n-1].EndLine = line
+			}
+			continue
+		}
+		ranges = append(ranges, NotableRange{
+			FileName:  fileName,
+			StartLine: line,
+			EndLine:   line,
+		})
+	}
+	return ranges
+}
@@ -0,0 +1,181 @@
+package selectivetesting
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	const baseDir = "/repo"
+
+	for _, tc := range []struct {
+		name          string
+		diff          string
+		wantFileNames []string
+		wantRanges    []NotableRange
+	}{
+		{
+			name: "modified",
+			diff: `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,3 +3,4 @@ func A() {
+ 	x := 1
+-	y := 2
++	y := 3
++	z := 4
+ 	return
+`,
+			wantFileNames: []string{"/repo/a.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/a.go", StartLine: 3, EndLine: 5}},
+		},
+		{
+			name: "added",
+			diff: `diff --git a/b.go b/b.go
+new file mode 100644
+--- /dev/null
++++ b/b.go
+@@ -0,0 +1,2 @@
++package b
++
+`,
+			wantFileNames: []string{"/repo/b.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/b.go", StartLine: 1, EndLine: 2}},
+		},
+		{
+			name: "deleted",
+			diff: `diff --git a/c.go b/c.go
+deleted file mode 100644
+--- a/c.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package c
+-
+`,
+			wantFileNames: []string{"/repo/c.go"},
+			wantRanges:    []NotableRange{},
+		},
+		{
+			name: "renamed",
+			diff: `diff --git a/old.go b/new.go
+similarity 90%
+rename from old.go
+rename to new.go
+--- a/old.go
++++ b/new.go
+@@ -1,2 +1,2 @@
+-package old
++package new
+ 
+`,
+			wantFileNames: []string{"/repo/old.go", "/repo/new.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/new.go", StartLine: 1, EndLine: 1}},
+		},
+		{
+			name: "quoted paths",
+			diff: `diff --git "a/with space.go" "b/with space.go"
+--- "a/with space.go"
++++ "b/with space.go"
+@@ -1 +1 @@
+-package a
++package b
+`,
+			wantFileNames: []string{"/repo/with space.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/with space.go", StartLine: 1, EndLine: 1}},
+		},
+		{
+			name: "timestamps",
+			diff: `--- a/a.go	2024-01-01 00:00:00
++++ b/a.go	2024-01-02 00:00:00
+@@ -1 +1 @@
+-package a
++package b
+`,
+			wantFileNames: []string{"/repo/a.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/a.go", StartLine: 1, EndLine: 1}},
+		},
+		{
+			name: "no newline at end of file",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ package a
+-var x = 1
+\ No newline at end of file
++var x = 2
+\ No newline at end of file
+--- a/b.go
++++ b/b.go
+@@ -1 +1 @@
+-package a
++package b
+`,
+			wantFileNames: []string{"/repo/a.go", "/repo/b.go"},
+			wantRanges: []NotableRange{
+				{FileName: "/repo/a.go", StartLine: 1, EndLine: 2},
+				{FileName: "/repo/b.go", StartLine: 1, EndLine: 1},
+			},
+		},
+		{
+			name: "zero-length new range",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -5,2 +4,0 @@
+-	x := 1
+-	y := 2
+`,
+			wantFileNames: []string{"/repo/a.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/a.go", StartLine: 4, EndLine: 5}},
+		},
+		{
+			name: "zero-length old range",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -4,0 +5,2 @@
++	x := 1
++	y := 2
+`,
+			wantFileNames: []string{"/repo/a.go"},
+			wantRanges:    []NotableRange{{FileName: "/repo/a.go", StartLine: 5, EndLine: 6}},
+		},
+		{
+			name: "separate hunks",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -2 +2 @@
+-var a = 1
++var a = 2
+@@ -10 +10 @@
+-var b = 1
++var b = 2
+`,
+			wantFileNames: []string{"/repo/a.go"},
+			wantRanges: []NotableRange{
+				// Removed lines also mark the line before them.
+				{FileName: "/repo/a.go", StartLine: 1, EndLine: 2},
+				{FileName: "/repo/a.go", StartLine: 9, EndLine: 10},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fileNames, ranges, err := ParseUnifiedDiff(strings.NewReader(tc.diff), baseDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fileNames, tc.wantFileNames) {
+				t.Errorf("got file names %q, want %q", fileNames, tc.wantFileNames)
+			}
+			if !reflect.DeepEqual(ranges, tc.wantRanges) {
+				t.Errorf("got ranges %v, want %v", ranges, tc.wantRanges)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiffInvalidHunk(t *testing.T) {
+	diff := "--- a/a.go\n+++ b/a.go\n@@ -x +1 @@\n"
+	if _, _, err := ParseUnifiedDiff(strings.NewReader(diff), "/repo"); err == nil {
+		t.Errorf("expected an error for an invalid hunk header")
+	}
+}
@@ -0,0 +1,143 @@
+package selectivetesting
+
+import (
+	"go/types"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+// analyzeDispatches links concrete methods to the interface methods they implement, so that changing an
+// implementation reaches code that only calls it through an interface.
+func (fa *FileAnalyzer) analyzeDispatches(pkgs []*packages.Package) {
+	// Interfaces may be declared in packages that are only loaded as dependencies.
+	ifaces := make([]*types.Named, 0)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil || !fa.isBasePkg(pkg.PkgPath) {
+			return
+		}
+		for _, named := range namedTypesOf(pkg.Types) {
+			if iface, ok := named.Underlying().(*types.Interface); ok && iface.NumMethods() > 0 {
+				ifaces = append(ifaces, named)
+			}
+		}
+	})
+
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+
+		for _, named := range namedTypesOf(pkg.Types) {
+			if types.IsInterface(named) {
+				continue
+			}
+
+			// Methods with pointer receivers are only in the method set of the pointer.
+			ptr := types.NewPointer(named)
+			for _, ifaceNamed := range ifaces {
+				iface := ifaceNamed.Underlying().(*types.Interface)
+				if !types.Implements(ptr, iface) {
+					continue
+				}
+				fa.addDispatches(ptr, iface)
+			}
+		}
+	}
+}
+
+func (fa *FileAnalyzer) addDispatches(t types.Type, iface *types.Interface) {
+	for i := 0; i < iface.NumMethods(); i++ {
+		ifaceMethod := iface.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(t, false, ifaceMethod.Pkg(), ifaceMethod.Name())
+		method, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+
+		ifaceMethodDef := fa.getDefinition(ifaceMethod)
+		methodDef := fa.getDefinition(method)
+		if ifaceMethodDef == nil || methodDef == nil {
+			continue
+		}
+
+		fa.addDispatch(fa.objNameOf(ifaceMethod), fa.objNameOf(method))
+	}
+}
+
+func (fa *FileAnalyzer) addDispatch(ifaceMethodObjName, methodObjName string) {
+	fa.addEdge(ifaceMethodObjName, methodObjName)
+	methodObjNames := util.MapGetOrCreate(fa.dispatches, ifaceMethodObjName, func() util.Set[string] { return util.NewSet[string]() })
+	methodObjNames.Add(methodObjName)
+}
+
+// isDispatch reports whether the usage is an interface method dispatching to an implementation. It takes no step, as
+// calling the interface method is calling the implementation.
+func (fa *FileAnalyzer) isDispatch(userObjName, usedObjName string) bool {
+	return fa.dispatches[userObjName].Has(usedObjName)
+}
+
+// stepsToUsed returns the fewest steps from the given objects to every object they use, directly or through others,
+// within maxSteps. The given objects are at zero steps.
+func (fa *FileAnalyzer) stepsToUsed(objNames []string, maxSteps int) map[string]int {
+	steps := make(map[string]int, len(objNames))
+	frontier := make([]string, 0, len(objNames))
+	for _, objName := range objNames {
+		if _, ok := steps[objName]; !ok {
+			steps[objName] = 0
+			frontier = append(frontier, objName)
+		}
+	}
+
+	for step := 0; len(frontier) > 0; step++ {
+		next := make([]string, 0)
+		// Dispatches extend the frontier of the current step.
+		for i := 0; i < len(frontier); i++ {
+			objName := frontier[i]
+			// Reached again with fewer steps since it was queued.
+			if steps[objName] != step {
+				continue
+			}
+
+			for usedObjName := range fa.definitions[objName].usingObjNames {
+				if fa.definitions[usedObjName] == nil {
+					continue
+				}
+				usedStep := step + 1
+				if fa.isDispatch(objName, usedObjName) {
+					usedStep = step
+				}
+				if prevStep, ok := steps[usedObjName]; (ok && prevStep <= usedStep) || usedStep > maxSteps {
+					continue
+				}
+
+				steps[usedObjName] = usedStep
+				if usedStep == step {
+					frontier = append(frontier, usedObjName)
+				} else {
+					next = append(next, usedObjName)
+				}
+			}
+		}
+		frontier = next
+	}
+	return steps
+}
+
+// namedTypesOf returns non-generic named types declared in the package scope.
+func namedTypesOf(pkg *types.Package) []*types.Named {
+	nameds := make([]*types.Named, 0)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		named, ok := typeName.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		nameds = append(nameds, named)
+	}
+	return nameds
+}
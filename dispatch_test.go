@@ -0,0 +1,50 @@
+package selectivetesting
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDispatchTakesNoStep(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"iface/iface.go": "package iface\n\ntype Doer interface{ Do() int }\n",
+		"impl/impl.go":   "package impl\n\ntype T struct{}\n\nfunc (T) Do() int {\n\treturn 1\n}\n",
+		"app/app.go":     "package app\n",
+		"app/app_test.go": `package app
+
+import (
+	"testing"
+
+	"example.com/m/iface"
+	"example.com/m/impl"
+)
+
+func newDoer() iface.Doer { return impl.T{} }
+
+func TestViaInterface(t *testing.T) {
+	_ = newDoer().Do()
+}
+`,
+	})
+
+	// Calling the interface method is one step away, as calling the implementation directly would be.
+	fa := loadTestModule(t, dir, nil, WithDepth(1), WithNotableRanges(NotableRange{
+		FileName:  filepath.Join(dir, "impl", "impl.go"),
+		StartLine: 6,
+		EndLine:   6,
+	}))
+	testedPkgs, _ := fa.DetermineTests()
+	if testedPkg := testedPkgs[testModulePath+"/app"]; testedPkg == nil || !testedPkg.Has("TestViaInterface") {
+		t.Errorf("TestViaInterface is not selected")
+	}
+
+	deps, err := fa.Dependencies("TestViaInterface", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dep := range deps {
+		if fa.definitions[dep.ObjName].name == "Do" && dep.PkgPath == testModulePath+"/impl" && dep.Steps != 1 {
+			t.Errorf("implementation is %d steps away, want 1", dep.Steps)
+		}
+	}
+}
@@ -4,6 +4,8 @@ type traversal struct {
 	objName   string
 	stepsLeft int
 	index     int
+	// Traversal the object has been reached from with the most steps left, nil for notable objects.
+	prev *traversal
 }
 
 type traversalPQ []*traversal
@@ -13,6 +15,10 @@ func (h traversalPQ) Len() int {
 }
 
 func (h traversalPQ) Less(i, j int) bool {
+	// Ties are broken by name so that the objects are reached from the same ones on every run.
+	if h[i].stepsLeft == h[j].stepsLeft {
+		return h[i].objName < h[j].objName
+	}
 	return h[i].stepsLeft > h[j].stepsLeft
 }
 
@@ -0,0 +1,164 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+const embedDirective = "//go:embed"
+
+// searchEmbeds attaches the files embedded through go:embed directives to the variables they initialize.
+func (fa *FileAnalyzer) searchEmbeds(pkg *packages.Package) {
+	if len(pkg.EmbedFiles) == 0 {
+		return
+	}
+
+	for _, astFile := range pkg.Syntax {
+		fileName := sourcePositionOf(pkg.Fset, astFile.Package).Filename
+		if _, removed := fa.removedFiles[fileName]; removed {
+			continue
+		}
+		pkgDir := filepath.Dir(fileName)
+
+		for _, d := range astFile.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok || decl.Tok != token.VAR {
+				continue
+			}
+			for _, s := range decl.Specs {
+				spec := s.(*ast.ValueSpec)
+				patterns := embedPatternsOf(spec.Doc)
+				// Directives of ungrouped declarations belong to the declaration itself.
+				if !decl.Lparen.IsValid() {
+					patterns = append(patterns, embedPatternsOf(decl.Doc)...)
+				}
+				if len(patterns) == 0 || len(spec.Names) != 1 {
+					continue
+				}
+
+				obj := pkg.TypesInfo.Defs[spec.Names[0]]
+				if obj == nil {
+					continue
+				}
+				def := fa.definitions[fa.objNameOf(obj)]
+				if def == nil {
+					continue
+				}
+
+				for _, embedFile := range pkg.EmbedFiles {
+					relPath, err := filepath.Rel(pkgDir, embedFile)
+					if err != nil {
+						continue
+					}
+					for _, pattern := range patterns {
+						// Test variants of the package embed the same files.
+						if matchEmbedPattern(pattern, filepath.ToSlash(relPath)) && !slices.Contains(def.embedFiles, embedFile) {
+							def.embedFiles = append(def.embedFiles, embedFile)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func embedPatternsOf(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+
+	patterns := make([]string, 0)
+	for _, comment := range doc.List {
+		args, ok := strings.CutPrefix(comment.Text, embedDirective)
+		if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+			continue
+		}
+		patterns = append(patterns, parseEmbedPatterns(args)...)
+	}
+	return patterns
+}
+
+// parseEmbedPatterns splits the arguments of a go:embed directive, which are separated by spaces and may be quoted.
+func parseEmbedPatterns(args string) []string {
+	patterns := make([]string, 0)
+	for {
+		args = strings.TrimLeft(args, " \t")
+		if args == "" {
+			return patterns
+		}
+
+		end := strings.IndexAny(args, " \t")
+		if args[0] == '"' || args[0] == '`' {
+			end = quotedEnd(args)
+		}
+		if end < 0 {
+			end = len(args)
+		}
+
+		pattern := args[:end]
+		if unquoted, err := strconv.Unquote(pattern); err == nil {
+			pattern = unquoted
+		}
+		patterns = append(patterns, pattern)
+		args = args[end:]
+	}
+}
+
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[0] == '"' && s[i] == '\\':
+			i++
+		case s[i] == s[0]:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// matchEmbedPattern reports whether the pattern embeds the file, given by its slash-separated path relative to the
+// package directory.
+func matchEmbedPattern(pattern, relPath string) bool {
+	pattern, all := strings.CutPrefix(pattern, "all:")
+	if ok, _ := path.Match(pattern, relPath); ok {
+		return true
+	}
+
+	for dir := path.Dir(relPath); dir != "."; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); !ok {
+			continue
+		}
+		// Files within embedded directories are left out when hidden, unless the pattern starts with all:.
+		if all {
+			return true
+		}
+		for _, elem := range strings.Split(strings.TrimPrefix(relPath, dir+"/"), "/") {
+			if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// embeddingObjNames indexes the variables by the files embedded into them.
+func (fa *FileAnalyzer) embeddingObjNames() map[string]util.Set[string] {
+	objNames := make(map[string]util.Set[string])
+	for objName, def := range fa.definitions {
+		for _, embedFile := range def.embedFiles {
+			embeddingObjs := util.MapGetOrCreate(objNames, embedFile, func() util.Set[string] { return util.NewSet[string]() })
+			embeddingObjs.Add(objName)
+		}
+	}
+	return objNames
+}
@@ -0,0 +1,58 @@
+package selectivetesting
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseEmbedPatterns(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args string
+		want []string
+	}{
+		{name: "empty", args: "", want: []string{}},
+		{name: "single", args: " a.txt", want: []string{"a.txt"}},
+		{name: "several", args: " a.txt\tb/*.json  c", want: []string{"a.txt", "b/*.json", "c"}},
+		{name: "double quoted", args: ` "with space.txt" a.txt`, want: []string{"with space.txt", "a.txt"}},
+		{name: "escaped quote", args: ` "a\"b.txt"`, want: []string{`a"b.txt`}},
+		{name: "back quoted", args: " `with space.txt`", want: []string{"with space.txt"}},
+		{name: "all prefix", args: " all:static", want: []string{"all:static"}},
+		{name: "unterminated quote", args: ` "a.txt`, want: []string{`"a.txt`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseEmbedPatterns(tc.args); !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchEmbedPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		relPath string
+		want    bool
+	}{
+		{pattern: "a.txt", relPath: "a.txt", want: true},
+		{pattern: "a.txt", relPath: "b.txt", want: false},
+		{pattern: "*.txt", relPath: "a.txt", want: true},
+		{pattern: "*.txt", relPath: "dir/a.txt", want: false},
+		{pattern: "dir/*.txt", relPath: "dir/a.txt", want: true},
+		{pattern: "static", relPath: "static/css/a.css", want: true},
+		{pattern: "static", relPath: "staticfile", want: false},
+		{pattern: "static", relPath: "static/.hidden", want: false},
+		{pattern: "static", relPath: "static/_partial/a.html", want: false},
+		{pattern: "all:static", relPath: "static/.hidden", want: true},
+		{pattern: "all:static", relPath: "static/_partial/a.html", want: true},
+		// Hidden files named by the pattern itself are embedded.
+		{pattern: "static/.hidden", relPath: "static/.hidden", want: true},
+		{pattern: "st*", relPath: "static/a.css", want: true},
+	} {
+		t.Run(tc.pattern+" "+tc.relPath, func(t *testing.T) {
+			if got := matchEmbedPattern(tc.pattern, tc.relPath); got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+var ginkgoPkgPaths = util.NewSet("github.com/onsi/ginkgo/v2", "github.com/onsi/ginkgo")
+
+var (
+	ginkgoContainers = util.NewSet("Describe", "Context", "When", "DescribeTable")
+	ginkgoSpecs      = util.NewSet("It", "Specify", "Entry")
+)
+
+type ginkgoNodeKind int
+
+const (
+	ginkgoNodeNone ginkgoNodeKind = iota
+	ginkgoNodeContainer
+	ginkgoNodeSpec
+)
+
+type GinkgoNode struct {
+	// Full text of the node, as matched by the focus of Ginkgo.
+	Text      string
+	Container bool
+}
+
+// searchGinkgoNodes records containers and specs with literal texts declared at the top level of test files, so
+// that the specs reaching changed code can be focused on.
+func (fa *FileAnalyzer) searchGinkgoNodes(pkg *packages.Package) {
+	for _, astFile := range pkg.Syntax {
+		file := pkg.Fset.File(astFile.Pos())
+		if _, removed := fa.removedFiles[file.Name()]; removed || !strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+
+		for _, d := range astFile.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok || decl.Tok != token.VAR {
+				continue
+			}
+			for _, s := range decl.Specs {
+				for _, value := range s.(*ast.ValueSpec).Values {
+					fa.addGinkgoNodes(pkg, file, value, nil)
+				}
+			}
+		}
+	}
+}
+
+func (fa *FileAnalyzer) addGinkgoNodes(pkg *packages.Package, file *token.File, root ast.Node, parentTexts []string) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		kind := ginkgoNodeKindOf(pkg.TypesInfo, call)
+		if kind == ginkgoNodeNone {
+			return true
+		}
+		// Nodes without literal texts cannot be focused on, so they are left to their enclosing nodes as a whole.
+		text, ok := stringLitArgOf(call)
+		if !ok {
+			return false
+		}
+
+		texts := append(parentTexts[:len(parentTexts):len(parentTexts)], text)
+		fullText := strings.Join(texts, " ")
+		pkgPath := strings.TrimSuffix(pkg.PkgPath, "_test")
+		startLine := file.Line(call.Pos())
+
+		fa.putDefinition("ginkgo "+pkgPath+" "+strconv.Quote(fullText)+" "+file.Name()+":"+strconv.Itoa(startLine), &definition{
+			node:           call,
+			pkgPath:        pkgPath,
+			name:           fullText,
+			fileName:       file.Name(),
+			startLine:      startLine,
+			endLine:        file.Line(call.End()),
+			usedByObjNames: util.NewSet[string](),
+			usingObjNames:  util.NewSet[string](),
+			ginkgoNode:     &GinkgoNode{Text: fullText, Container: kind == ginkgoNodeContainer},
+		})
+
+		for _, arg := range call.Args[1:] {
+			fa.addGinkgoNodes(pkg, file, arg, texts)
+		}
+		return false
+	})
+}
+
+func ginkgoNodeKindOf(info *types.Info, call *ast.CallExpr) ginkgoNodeKind {
+	obj, ok := calleeOf(info, call)
+	if !ok || obj.Pkg() == nil || !ginkgoPkgPaths.Has(obj.Pkg().Path()) {
+		return ginkgoNodeNone
+	}
+
+	name := obj.Name()
+	// Focused, pending and skipped variants, such as FIt and XDescribe.
+	if len(name) > 1 && strings.ContainsRune("FPX", rune(name[0])) &&
+		(ginkgoContainers.Has(name[1:]) || ginkgoSpecs.Has(name[1:])) {
+		name = name[1:]
+	}
+
+	switch {
+	case ginkgoContainers.Has(name):
+		return ginkgoNodeContainer
+	case ginkgoSpecs.Has(name):
+		return ginkgoNodeSpec
+	}
+	return ginkgoNodeNone
+}
+
+// isGinkgoBootstrap reports whether the call runs the Ginkgo specs of the package.
+func isGinkgoBootstrap(info *types.Info, call *ast.CallExpr) bool {
+	obj, ok := calleeOf(info, call)
+	return ok && obj.Pkg() != nil && ginkgoPkgPaths.Has(obj.Pkg().Path()) && obj.Name() == "RunSpecs"
+}
+
+// calleeOf returns the function or function variable being called, such as Context in Ginkgo.
+func calleeOf(info *types.Info, call *ast.CallExpr) (types.Object, bool) {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	case *ast.Ident:
+		ident = fun
+	default:
+		return nil, false
+	}
+	switch obj := info.Uses[ident].(type) {
+	case *types.Func:
+		return obj, true
+	case *types.Var:
+		return obj, obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+	}
+	return nil, false
+}
+
+// ginkgoBootstrapsOf returns the object names of the tests running the Ginkgo specs of the package.
+func (fa *FileAnalyzer) ginkgoBootstrapsOf(pkgPath string) []string {
+	objNames := make([]string, 0)
+	for objName := range fa.ginkgoBootstraps {
+		if def := fa.definitions[objName]; def != nil && def.pkgPath == pkgPath {
+			objNames = append(objNames, objName)
+		}
+	}
+	return objNames
+}
+
+// addGinkgoNode selects a container or spec run by the test.
+func (tp *TestedPackage) addGinkgoNode(testName string, node GinkgoNode) {
+	// The test is already run with every spec.
+	if tp.Names.Has("*") || (tp.Names.Has(testName) && tp.GinkgoNodes[testName] == nil) {
+		return
+	}
+	tp.addName(testName)
+
+	nodes := util.MapGetOrCreate(tp.GinkgoNodes, testName, func() util.Set[GinkgoNode] { return util.NewSet[GinkgoNode]() })
+	nodes.Add(node)
+}
@@ -0,0 +1,63 @@
+package selectivetesting
+
+import "testing"
+
+func TestGinkgoFocusesOnInnermostNode(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"go.mod": `module example.com/m
+
+go 1.21
+
+require github.com/onsi/ginkgo/v2 v2.0.0
+
+replace github.com/onsi/ginkgo/v2 => ./ginkgo
+`,
+		"ginkgo/go.mod": "module github.com/onsi/ginkgo/v2\n\ngo 1.21\n",
+		"ginkgo/ginkgo.go": `package ginkgo
+
+import "testing"
+
+func Describe(text string, args ...interface{}) bool { return true }
+
+func It(text string, args ...interface{}) bool { return true }
+
+func RunSpecs(t *testing.T, description string) bool { return true }
+`,
+		"a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"a/a_test.go": `package a
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestSuite(t *testing.T) {
+	RunSpecs(t, "A")
+}
+
+var _ = Describe("A", func() {
+	It("returns one", func() { A() })
+
+	It("does nothing", func() {})
+})
+
+func TestB(t *testing.T) {}
+`,
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestSuite")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "TestB")
+
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg == nil {
+		return
+	}
+	nodes := testedPkg.GinkgoNodes["TestSuite"]
+	if wantNode := (GinkgoNode{Text: "A returns one"}); nodes.Len() != 1 || !nodes.Has(wantNode) {
+		t.Errorf("got ginkgo nodes %v, want only %v", nodes.ToSlice(), wantNode)
+	}
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,31 +9,93 @@ import (
 )
 
 func Run() error {
-	cfg, inputPaths, err := parseArgs()
-	if err != nil {
-		return fmt.Errorf("configuration error: %w", err)
+	args := os.Args[1:]
+	// Changed files named after a command, such as a file named stats, are still taken as files.
+	if len(args) > 0 && !isExistingPath(args[0]) {
+		switch args[0] {
+		case "explain":
+			return runExplain(args[1:])
+		case "deps":
+			return runDeps(args[1:])
+		case "stats":
+			return runStats(args[1:])
+		case "untested":
+			return runUntested(args[1:])
+		}
 	}
-	basePkg, absInputPaths, options, err := forAnalyzer(cfg, inputPaths)
+	return runSelect(args)
+}
+
+func isExistingPath(arg string) bool {
+	_, err := os.Stat(arg)
+	return err == nil
+}
+
+func runSelect(args []string) error {
+	cfg, inputPaths, err := parseArgs(flag.NewFlagSet(os.Args[0], flag.ExitOnError), args)
 	if err != nil {
 		return fmt.Errorf("configuration error: %w", err)
 	}
-	fa := selectivetesting.NewFileAnalyzer(basePkg, absInputPaths, options...)
-	if err := fa.Load(); err != nil {
-		return fmt.Errorf("could not load packages: %w", err)
+	fa, err := loadFileAnalyzer(cfg, inputPaths)
+	if err != nil {
+		return err
 	}
 	crudeTestedPkgs, uniqueTestCount := fa.DetermineTests()
-	testedPkgs := cleanTestedPkgs(basePkg, crudeTestedPkgs)
+	testedPkgs := cleanTestedPkgs(crudeTestedPkgs)
 	if cfg.AnalyzerOutPath != "" {
 		if err := writeFileAnalyzerTo(cfg.AnalyzerOutPath, fa); err != nil {
 			return err
 		}
 	}
+
+	profileTestedPkgs := fa.ProfileTests(crudeTestedPkgs)
+	profiles := make([]*profileTesting, 0, len(cfg.Profiles))
+	for _, profile := range cfg.Profiles {
+		profiles = append(profiles, &profileTesting{
+			Name:       profile.Name,
+			BuildFlags: fa.BuildFlagsOf(profile.analyzerProfile()),
+			Env:        profile.env(),
+			Groups:     groupBy(cleanTestedPkgs(profileTestedPkgs[profile.Name]), cfg.Groups, cfg.OutputEmptyGroups),
+		})
+	}
+
 	if !cfg.GoTest.Run {
 		testedPkgGroups := groupBy(testedPkgs, cfg.Groups, cfg.OutputEmptyGroups)
 		return jsonTo(os.Stdout, cfg.PrettyOutput, testing{
 			UniqueTestCount: uniqueTestCount,
 			Groups:          testedPkgGroups,
+			Profiles:        profiles,
 		})
 	}
-	return runTests(cfg.ModuleDir, cfg.GoTest.Args, cfg.GoTest.Parallel, testedPkgs)
+	if len(profiles) == 0 {
+		return runTests(cfg.ModuleDir, cfg.GoTest.Args, cfg.GoTest.Parallel, testedPkgs, nil, nil)
+	}
+
+	// Tests compiled under several profiles are run under each of them.
+	var runErrs multiError
+	for _, profile := range profiles {
+		groupedTestedPkgs := make([]*testedPackage, 0)
+		for _, group := range profile.Groups {
+			groupedTestedPkgs = append(groupedTestedPkgs, group.TestedPkgs...)
+		}
+		if err := runTests(cfg.ModuleDir, cfg.GoTest.Args, cfg.GoTest.Parallel, groupedTestedPkgs, profile.BuildFlags, profile.Env); err != nil {
+			runErrs = append(runErrs, err)
+		}
+	}
+	if len(runErrs) > 0 {
+		return runErrs
+	}
+	return nil
+}
+
+func loadFileAnalyzer(cfg config, inputPaths []string) (*selectivetesting.FileAnalyzer, error) {
+	basePkg, absInputPaths, options, err := forAnalyzer(cfg, inputPaths)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	fa := selectivetesting.NewFileAnalyzer(basePkg, absInputPaths, options...)
+	if err := fa.Load(); err != nil {
+		return nil, fmt.Errorf("could not load packages: %w", err)
+	}
+	return fa, nil
 }
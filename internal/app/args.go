@@ -10,29 +10,41 @@ import (
 	"github.com/ezraisw/go-selectivetesting"
 )
 
-func parseArgs() (cfg config, notablePaths []string, err error) {
+func parseArgs(fs *flag.FlagSet, args []string) (cfg config, notablePaths []string, err error) {
 	var (
 		cfgPath     string
 		cfgFromFlag config
 	)
 
-	flag.StringVar(&cfgPath, "cfgpath", "", "Config file to use for command configuration.")
-
-	flag.StringVar(&cfgFromFlag.RelativePath, "relativepath", "", "Relative path from current working directory for input files.")
-	flag.BoolVar(&cfgFromFlag.PrettyOutput, "prettyoutput", false, "Whether to output indented json. Will be ignored if -gotestrun is set.")
-	flag.Var(&cfgFromFlag.Patterns, "patterns", "Patterns to use for package search.")
-	flag.StringVar(&cfgFromFlag.ModuleDir, "moduledir", "", "Path to the directory of the module.")
-	flag.StringVar(&cfgFromFlag.BasePkg, "basepkg", "", "Base package path/module name, will be used instead of <modulepath>/go.mod.")
-	flag.IntVar(&cfgFromFlag.Depth, "depth", 0, "Depth of the test search from input files.")
-	flag.Var(&cfgFromFlag.BuildFlags, "buildflags", "Build flags to use.")
-	flag.BoolVar(&cfgFromFlag.TestAll, "testall", false, "Override output with list of all packages within its groups.")
-	flag.StringVar(&cfgFromFlag.AnalyzerOutPath, "analyzeroutpath", "", "Path to output debug information for analyzer.")
-	flag.BoolVar(&cfgFromFlag.GoTest.Run, "gotestrun", false, "Whether to run go test with the result of the output. Will output the testing information instead.")
-	flag.StringVar(&cfgFromFlag.GoTest.Args, "gotestargs", "", "The arguments to pass to the go test command. The arguments will be put at the end of the command.")
-	flag.IntVar(&cfgFromFlag.GoTest.Parallel, "gotestparallel", 0, "Maximum number of parallel go test processes. If not set, it will run the test in series.")
-	flag.BoolVar(&cfg.OutputEmptyGroups, "outputemptygroups", false, "Whether to output untested groups as a group with empty arrays. Default group included.")
-
-	flag.Parse()
+	fs.StringVar(&cfgPath, "cfgpath", "", "Config file to use for command configuration.")
+
+	fs.StringVar(&cfgFromFlag.RelativePath, "relativepath", "", "Relative path from current working directory for input files.")
+	fs.BoolVar(&cfgFromFlag.PrettyOutput, "prettyoutput", false, "Whether to output indented json. Will be ignored if -gotestrun is set.")
+	fs.Var(&cfgFromFlag.Patterns, "patterns", "Patterns to use for package search.")
+	fs.StringVar(&cfgFromFlag.ModuleDir, "moduledir", "", "Path to the directory of the module.")
+	fs.StringVar(&cfgFromFlag.BasePkg, "basepkg", "", "Base package path/module name, will be used instead of <modulepath>/go.mod.")
+	fs.Var(&cfgFromFlag.BasePkgs, "basepkgs", "Additional base package paths/module names, will be used instead of the modules of <modulepath>/go.work.")
+	fs.IntVar(&cfgFromFlag.Depth, "depth", 0, "Depth of the test search from input files.")
+	fs.Var(&cfgFromFlag.BuildFlags, "buildflags", "Build flags to use.")
+	fs.BoolVar(&cfgFromFlag.TestAll, "testall", false, "Override output with list of all packages within its groups.")
+	fs.StringVar(&cfgFromFlag.AnalyzerOutPath, "analyzeroutpath", "", "Path to output debug information for analyzer.")
+	fs.BoolVar(&cfgFromFlag.GoTest.Run, "gotestrun", false, "Whether to run go test with the result of the output. Will output the testing information instead.")
+	fs.StringVar(&cfgFromFlag.GoTest.Args, "gotestargs", "", "The arguments to pass to the go test command. The arguments will be put at the end of the command.")
+	fs.IntVar(&cfgFromFlag.GoTest.Parallel, "gotestparallel", 0, "Maximum number of parallel go test processes. If not set, it will run the test in series.")
+	fs.StringVar(&cfgFromFlag.Since, "since", "", "Git revision to compare the working tree against for changed files.")
+	fs.StringVar(&cfgFromFlag.Range, "range", "", "Git revision range <base>..<head> for changed files, where head must be checked out or left out for the working tree. Use <base>...<head> to compare against their merge-base.")
+	fs.StringVar(&cfgFromFlag.TargetBranch, "targetbranch", "", "Git branch whose merge-base with HEAD is compared against the working tree for changed files.")
+	fs.BoolVar(&cfgFromFlag.Hunks, "hunks", false, "Whether to only consider definitions overlapping changed lines of the git revisions instead of whole files.")
+	fs.StringVar(&cfgFromFlag.DiffPath, "diffpath", "", "Path to a unified diff to take changed files and lines from. Use - to read from stdin.")
+	fs.BoolVar(&cfgFromFlag.LoadDeleted, "loaddeleted", false, "Whether to load deleted and renamed go files from the base revision to find the tests that used them.")
+	fs.BoolVar(&cfgFromFlag.SemanticDiff, "semanticdiff", false, "Whether to only consider declarations that changed after ignoring comments and formatting against the base revision.")
+	fs.StringVar(&cfgFromFlag.CachePath, "cachepath", "", "Path to a file to persist the usage graph in, so that only changed packages are loaded again on later runs.")
+	fs.BoolVar(&cfgFromFlag.TestAllOnToolchainChange, "testallontoolchainchange", false, "Whether to test everything when the go directive or toolchain of go.mod changes between the git revisions.")
+	fs.BoolVar(&cfg.OutputEmptyGroups, "outputemptygroups", false, "Whether to output untested groups as a group with empty arrays. Default group included.")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, err
+	}
 
 	var cfgFromFile config
 	if cfgPath != "" {
@@ -48,15 +60,15 @@ func parseArgs() (cfg config, notablePaths []string, err error) {
 	cfg = cfgMerge(cfgFromFile, cfgFromFlag)
 
 	notablePaths = make([]string, 0)
-	for i := 0; i < flag.NArg(); i++ {
-		notablePaths = append(notablePaths, flag.Arg(i))
+	for i := 0; i < fs.NArg(); i++ {
+		notablePaths = append(notablePaths, fs.Arg(i))
 	}
 
 	return cfg, notablePaths, nil
 }
 
 func forAnalyzer(cfg config, inputPaths []string) (string, []string, []selectivetesting.Option, error) {
-	basePkg, err := cfg.getBasePkg()
+	basePkgs, err := cfg.getBasePkgs()
 	if err != nil {
 		return "", nil, nil, fmt.Errorf("error while getting base package: %w", err)
 	}
@@ -66,15 +78,99 @@ func forAnalyzer(cfg config, inputPaths []string) (string, []string, []selective
 		return "", nil, nil, fmt.Errorf("error while getting base path: %w", err)
 	}
 
-	absInputPaths := make([]string, len(inputPaths))
+	revs, ok, err := gitResolveRevisions(cfg.ModuleDir, cfg)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("error resolving git revisions: %w", err)
+	}
+
+	absInputPaths := make([]string, 0, len(inputPaths))
 	for _, input := range inputPaths {
 		absInput := filepath.Join(inputBasePath, input)
-		if _, err := os.Stat(absInput); err != nil {
+		if _, err := os.Stat(absInput); err != nil && !(cfg.LoadDeleted && os.IsNotExist(err)) {
 			return "", nil, nil, fmt.Errorf("error checking file: %w", err)
 		}
 		absInputPaths = append(absInputPaths, absInput)
 	}
 
+	extraOptions := []selectivetesting.Option{
+		selectivetesting.WithBasePkgs(basePkgs[1:]...),
+	}
+
+	// Relative patterns do not reach into every module of a workspace.
+	if len(cfg.Patterns) == 0 && len(basePkgs) > 1 {
+		patterns := make([]string, 0, len(basePkgs))
+		for _, basePkg := range basePkgs {
+			patterns = append(patterns, basePkg+"/...")
+		}
+		extraOptions = append(extraOptions, selectivetesting.WithPatterns(patterns...))
+	}
+
+	var changes []fileChange
+	if ok {
+		changes, err = gitChangedFiles(cfg.ModuleDir, revs)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error getting changed files: %w", err)
+		}
+		absInputPaths = append(absInputPaths, notablePathsOf(changes)...)
+
+		if cfg.Hunks {
+			ranges, err := gitChangedRanges(cfg.ModuleDir, revs)
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("error getting changed lines: %w", err)
+			}
+			extraOptions = append(extraOptions, selectivetesting.WithNotableRanges(ranges...))
+		}
+
+		moduleDiff, ok, err := gitModuleDiff(cfg.ModuleDir, revs, changes)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error comparing module requirements: %w", err)
+		}
+		if ok {
+			extraOptions = append(extraOptions, selectivetesting.WithChangedModules(moduleDiff.ChangedModules...))
+			if moduleDiff.ToolchainChanged && cfg.TestAllOnToolchainChange {
+				extraOptions = append(extraOptions, selectivetesting.WithTestAll(true))
+			}
+		}
+
+		if cfg.SemanticDiff {
+			baseFiles, err := gitBaseGoFiles(cfg.ModuleDir, revs.Base, changes)
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("error getting base files: %w", err)
+			}
+			extraOptions = append(extraOptions, selectivetesting.WithSemanticDiff(baseFiles))
+		}
+	} else if cfg.Hunks || cfg.SemanticDiff {
+		return "", nil, nil, fmt.Errorf("hunks and semanticDiff require git revisions to be set")
+	}
+
+	if cfg.DiffPath != "" {
+		diffPaths, ranges, err := readDiff(cfg.DiffPath, inputBasePath)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error reading diff: %w", err)
+		}
+		absInputPaths = append(absInputPaths, diffPaths...)
+		extraOptions = append(extraOptions, selectivetesting.WithNotableRanges(ranges...))
+	}
+
+	if cfg.LoadDeleted {
+		// Without explicit revisions, deleted files are taken from the last commit.
+		baseRev := "HEAD"
+		if ok {
+			baseRev = revs.Base
+		} else {
+			changes, err = gitChangedFiles(cfg.ModuleDir, revisions{Base: baseRev})
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("error getting changed files: %w", err)
+			}
+		}
+
+		removedFiles, err := gitRemovedGoFiles(cfg.ModuleDir, baseRev, absInputPaths, renamedPathsOf(changes))
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("error loading deleted files: %w", err)
+		}
+		extraOptions = append(extraOptions, selectivetesting.WithRemovedFiles(removedFiles))
+	}
+
 	pathReplacements := map[string]string{
 		"<<basepath>>": inputBasePath,
 	}
@@ -84,5 +180,19 @@ func forAnalyzer(cfg config, inputPaths []string) (string, []string, []selective
 		return "", nil, nil, fmt.Errorf("error setting options: %w", err)
 	}
 
-	return basePkg, absInputPaths, options, nil
+	return basePkgs[0], absInputPaths, append(options, extraOptions...), nil
+}
+
+func readDiff(path, basePath string) ([]string, []selectivetesting.NotableRange, error) {
+	if path == "-" {
+		return selectivetesting.ParseUnifiedDiff(os.Stdin, basePath)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return selectivetesting.ParseUnifiedDiff(file, basePath)
 }
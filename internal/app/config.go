@@ -1,6 +1,7 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -8,6 +9,7 @@ import (
 
 	"dario.cat/mergo"
 	"github.com/ezraisw/go-selectivetesting"
+	"github.com/ezraisw/go-selectivetesting/internal/util"
 	"golang.org/x/mod/modfile"
 )
 
@@ -32,12 +34,48 @@ type miscUsage struct {
 	} `json:"usedBy"`
 }
 
+// buildProfile is a build configuration to analyze and run tests under, on top of buildFlags.
+type buildProfile struct {
+	Name   string          `json:"name"`
+	Tags   commaSepStrings `json:"tags"`
+	GOOS   string          `json:"goos"`
+	GOARCH string          `json:"goarch"`
+	Env    []string        `json:"env"`
+}
+
+func (p buildProfile) buildFlags() []string {
+	if len(p.Tags) == 0 {
+		return []string{}
+	}
+	return []string{"-tags=" + strings.Join(p.Tags, ",")}
+}
+
+func (p buildProfile) analyzerProfile() selectivetesting.BuildProfile {
+	return selectivetesting.BuildProfile{
+		Name:       p.Name,
+		BuildFlags: p.buildFlags(),
+		Env:        p.env(),
+	}
+}
+
+func (p buildProfile) env() []string {
+	env := append([]string{}, p.Env...)
+	if p.GOOS != "" {
+		env = append(env, "GOOS="+p.GOOS)
+	}
+	if p.GOARCH != "" {
+		env = append(env, "GOARCH="+p.GOARCH)
+	}
+	return env
+}
+
 type config struct {
 	RelativePath      string          `json:"relativePath"`
 	PrettyOutput      bool            `json:"prettyOutput"`
 	Patterns          commaSepStrings `json:"patterns"`
 	ModuleDir         string          `json:"moduleDir"`
 	BasePkg           string          `json:"basePkg"`
+	BasePkgs          commaSepStrings `json:"basePkgs"`
 	Depth             int             `json:"depth"`
 	BuildFlags        commaSepStrings `json:"buildFlags"`
 	TestAll           bool            `json:"testAll"`
@@ -46,18 +84,68 @@ type config struct {
 	Groups            []group         `json:"groups"`
 	OutputEmptyGroups bool            `json:"outputEmptyGroups"`
 	MiscUsages        []miscUsage     `json:"miscUsages"`
+	Since             string          `json:"since"`
+	Range             string          `json:"range"`
+	TargetBranch      string          `json:"targetBranch"`
+	Hunks             bool            `json:"hunks"`
+	DiffPath          string          `json:"diffPath"`
+	LoadDeleted       bool            `json:"loadDeleted"`
+	SemanticDiff      bool            `json:"semanticDiff"`
+	CachePath         string          `json:"cachePath"`
+	Profiles          []buildProfile  `json:"profiles"`
+
+	TestAllOnToolchainChange bool `json:"testAllOnToolchainChange"`
 }
 
-func (cfg config) getBasePkg() (string, error) {
-	basePkg := cfg.BasePkg
-	if basePkg == "" {
-		goModData, err := os.ReadFile(filepath.Join(cfg.ModuleDir, "go.mod"))
+func (cfg config) getBasePkgs() ([]string, error) {
+	basePkgs := make([]string, 0)
+	if cfg.BasePkg != "" {
+		basePkgs = append(basePkgs, cfg.BasePkg)
+	}
+	basePkgs = append(basePkgs, cfg.BasePkgs...)
+	if len(basePkgs) > 0 {
+		return basePkgs, nil
+	}
+
+	goWorkPath := filepath.Join(cfg.ModuleDir, "go.work")
+	goWorkData, err := os.ReadFile(goWorkPath)
+	if err == nil {
+		return workspaceModulePaths(goWorkPath, goWorkData)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	goModData, err := os.ReadFile(filepath.Join(cfg.ModuleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	return []string{modfile.ModulePath(goModData)}, nil
+}
+
+func workspaceModulePaths(goWorkPath string, goWorkData []byte) ([]string, error) {
+	workFile, err := modfile.ParseWork(goWorkPath, goWorkData, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	modulePaths := make([]string, 0, len(workFile.Use))
+	for _, use := range workFile.Use {
+		moduleDir := use.Path
+		if !filepath.IsAbs(moduleDir) {
+			moduleDir = filepath.Join(filepath.Dir(goWorkPath), moduleDir)
+		}
+
+		goModData, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		basePkg = modfile.ModulePath(goModData)
+		modulePaths = append(modulePaths, modfile.ModulePath(goModData))
 	}
-	return basePkg, nil
+	if len(modulePaths) == 0 {
+		return nil, fmt.Errorf("no modules used in %s", goWorkPath)
+	}
+	return modulePaths, nil
 }
 
 func (cfg config) getInputBasePath() (string, error) {
@@ -87,6 +175,27 @@ func (cfg config) asOptions(pathReplacements map[string]string) ([]selectivetest
 		options = append(options, selectivetesting.WithBuildFlags(cfg.BuildFlags...))
 	}
 
+	if cfg.CachePath != "" {
+		options = append(options, selectivetesting.WithCachePath(cfg.CachePath))
+	}
+
+	if len(cfg.Profiles) > 0 {
+		profiles := make([]selectivetesting.BuildProfile, 0, len(cfg.Profiles))
+		profileNames := util.NewSet[string]()
+		for _, profile := range cfg.Profiles {
+			if profile.Name == "" {
+				return nil, fmt.Errorf("build profiles must be named")
+			}
+			if profileNames.Has(profile.Name) {
+				return nil, fmt.Errorf("duplicate build profile %s", profile.Name)
+			}
+			profileNames.Add(profile.Name)
+
+			profiles = append(profiles, profile.analyzerProfile())
+		}
+		options = append(options, selectivetesting.WithBuildProfiles(profiles...))
+	}
+
 	if cfg.TestAll {
 		options = append(options, selectivetesting.WithTestAll(cfg.TestAll))
 	}
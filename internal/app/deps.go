@@ -0,0 +1,89 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ezraisw/go-selectivetesting"
+)
+
+type dependencies struct {
+	Test     string               `json:"test"`
+	Packages []*dependencyPackage `json:"packages"`
+}
+
+type dependencyPackage struct {
+	PkgPath string            `json:"pkgPath"`
+	Files   []*dependencyFile `json:"files"`
+}
+
+type dependencyFile struct {
+	File    string             `json:"file"`
+	Objects []dependencyObject `json:"objects"`
+}
+
+type dependencyObject struct {
+	ObjName string `json:"objName"`
+	Line    int    `json:"line"`
+	// Number of usages between the test and the object.
+	Steps int `json:"steps"`
+}
+
+// runDeps prints the objects the given test depends on, grouped by package and file.
+func runDeps(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" deps", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s deps [flags] [<pkg>.]<TestName>\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Every object reachable from the test is listed unless -depth is set.")
+		fs.PrintDefaults()
+	}
+
+	cfg, positionalArgs, err := parseArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if len(positionalArgs) != 1 {
+		return fmt.Errorf("configuration error: expected exactly one test name")
+	}
+	fa, err := loadFileAnalyzer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	deps, err := fa.Dependencies(positionalArgs[0], cfg.Depth)
+	if err != nil {
+		return err
+	}
+	return jsonTo(os.Stdout, cfg.PrettyOutput, groupDependencies(positionalArgs[0], deps))
+}
+
+// groupDependencies groups the dependencies, which are sorted by package path and file name.
+func groupDependencies(testName string, deps []selectivetesting.Dependency) dependencies {
+	grouped := dependencies{
+		Test:     testName,
+		Packages: make([]*dependencyPackage, 0),
+	}
+
+	var (
+		pkg  *dependencyPackage
+		file *dependencyFile
+	)
+	for _, dep := range deps {
+		if pkg == nil || pkg.PkgPath != dep.PkgPath {
+			pkg = &dependencyPackage{PkgPath: dep.PkgPath, Files: make([]*dependencyFile, 0)}
+			grouped.Packages = append(grouped.Packages, pkg)
+			file = nil
+		}
+		if file == nil || file.File != dep.FileName {
+			file = &dependencyFile{File: dep.FileName, Objects: make([]dependencyObject, 0)}
+			pkg.Files = append(pkg.Files, file)
+		}
+		file.Objects = append(file.Objects, dependencyObject{
+			ObjName: dep.ObjName,
+			Line:    dep.StartLine,
+			Steps:   dep.Steps,
+		})
+	}
+	return grouped
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting"
+)
+
+// runExplain prints the usage chains leading to the given test from the input files.
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" explain", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s explain [flags] [<pkg>.]<TestName> [files...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	cfg, positionalArgs, err := parseArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if len(positionalArgs) == 0 {
+		return fmt.Errorf("configuration error: missing test name to explain")
+	}
+	fa, err := loadFileAnalyzer(cfg, positionalArgs[1:])
+	if err != nil {
+		return err
+	}
+	crudeTestedPkgs, _ := fa.DetermineTests()
+
+	pkgPath, testName := splitTestName(positionalArgs[0])
+	pkgPaths := make([]string, 0, len(crudeTestedPkgs))
+	for crudePkgPath, tp := range crudeTestedPkgs {
+		if (pkgPath == "" || pkgPath == crudePkgPath) && tp.Has(testName) {
+			pkgPaths = append(pkgPaths, crudePkgPath)
+		}
+	}
+	if len(pkgPaths) == 0 {
+		return fmt.Errorf("%s is not selected", positionalArgs[0])
+	}
+	sort.Strings(pkgPaths)
+
+	for _, pkgPath := range pkgPaths {
+		writeExplanation(os.Stdout, pkgPath, testName, crudeTestedPkgs[pkgPath])
+	}
+	return nil
+}
+
+// splitTestName splits a test name optionally qualified by its package path.
+func splitTestName(name string) (pkgPath, testName string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+func writeExplanation(w io.Writer, pkgPath, testName string, tp *selectivetesting.TestedPackage) {
+	fmt.Fprintf(w, "%s.%s\n", pkgPath, testName)
+
+	chain, ok := tp.Reasons[testName]
+	if !ok {
+		chain, ok = tp.Reasons["*"]
+		if ok {
+			fmt.Fprintln(w, "  every test of the package is selected through:")
+		}
+	}
+	if !ok {
+		fmt.Fprintln(w, "  selected without a usage chain, such as through changed module requirements")
+		return
+	}
+	for _, hop := range chain {
+		fmt.Fprintf(w, "  %s (%s:%d)\n", hop.ObjName, hop.FileName, hop.StartLine)
+	}
+}
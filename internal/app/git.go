@@ -0,0 +1,376 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting"
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+type fileChange struct {
+	Status  byte
+	Path    string
+	OldPath string
+}
+
+type revisions struct {
+	Base string
+	// Empty head means the working tree.
+	Head string
+}
+
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	stderrBuf := &bytes.Buffer{}
+	cmd.Stderr = stderrBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderrBuf.String()))
+	}
+	return out, nil
+}
+
+func gitTopLevel(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitMergeBase(dir, a, b string) (string, error) {
+	out, err := runGit(dir, "merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitResolveRevisions(dir string, cfg config) (revisions, bool, error) {
+	set := 0
+	for _, v := range []string{cfg.Since, cfg.Range, cfg.TargetBranch} {
+		if v != "" {
+			set++
+		}
+	}
+	if set == 0 {
+		return revisions{}, false, nil
+	}
+	if set > 1 {
+		return revisions{}, false, fmt.Errorf("only one of since, range and targetBranch can be set")
+	}
+
+	switch {
+	case cfg.Since != "":
+		return revisions{Base: cfg.Since}, true, nil
+
+	case cfg.TargetBranch != "":
+		base, err := gitMergeBase(dir, cfg.TargetBranch, "HEAD")
+		if err != nil {
+			return revisions{}, false, err
+		}
+		return revisions{Base: base}, true, nil
+	}
+
+	// Three dots means the base is the merge-base of both sides, same as git diff.
+	if base, head, ok := strings.Cut(cfg.Range, "..."); ok {
+		if err := gitCheckCheckedOut(dir, head); err != nil {
+			return revisions{}, false, err
+		}
+		mergeBase, err := gitMergeBase(dir, base, "HEAD")
+		if err != nil {
+			return revisions{}, false, err
+		}
+		return revisions{Base: mergeBase, Head: head}, true, nil
+	}
+
+	base, head, ok := strings.Cut(cfg.Range, "..")
+	if !ok || base == "" {
+		return revisions{}, false, fmt.Errorf("invalid range %q, expected <base>..<head>", cfg.Range)
+	}
+	if err := gitCheckCheckedOut(dir, head); err != nil {
+		return revisions{}, false, err
+	}
+	return revisions{Base: base, Head: head}, true, nil
+}
+
+// gitCheckCheckedOut checks that the head of a range is what the packages are loaded from, which is the working tree.
+// An empty head stands for the working tree itself, while any other must be HEAD without uncommitted changes.
+func gitCheckCheckedOut(dir, head string) error {
+	if head == "" {
+		return nil
+	}
+
+	out, err := runGit(dir, "rev-parse", head+"^{commit}", "HEAD")
+	if err != nil {
+		return err
+	}
+	commits := strings.Fields(string(out))
+	if len(commits) != 2 || commits[0] != commits[1] {
+		return fmt.Errorf("range head %q is not checked out, check it out or leave the head out to use the working tree", head)
+	}
+
+	out, err = runGit(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		return fmt.Errorf("working tree has uncommitted changes not in range head %q, leave the head out to include them", head)
+	}
+	return nil
+}
+
+func gitChangedFiles(dir string, revs revisions) ([]fileChange, error) {
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"diff", "--name-status", "-z", "-M", revs.Base}
+	if revs.Head != "" {
+		args = append(args, revs.Head)
+	}
+	args = append(args, "--")
+
+	out, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	changes := make([]fileChange, 0)
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+		status := fields[i][0]
+
+		// Renames and copies carry both the source and the destination path.
+		if status == 'R' || status == 'C' {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff output")
+			}
+			changes = append(changes, fileChange{
+				Status:  status,
+				OldPath: filepath.Join(topLevel, fields[i+1]),
+				Path:    filepath.Join(topLevel, fields[i+2]),
+			})
+			i += 2
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return nil, fmt.Errorf("unexpected git diff output")
+		}
+		changes = append(changes, fileChange{
+			Status: status,
+			Path:   filepath.Join(topLevel, fields[i+1]),
+		})
+		i++
+	}
+
+	return changes, nil
+}
+
+func gitChangedRanges(dir string, revs revisions) ([]selectivetesting.NotableRange, error) {
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"diff", "-U0", "-M", "--no-color", "--no-ext-diff", revs.Base}
+	if revs.Head != "" {
+		args = append(args, revs.Head)
+	}
+	args = append(args, "--")
+
+	out, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ranges, err := selectivetesting.ParseUnifiedDiff(bytes.NewReader(out), topLevel)
+	if err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+func gitShow(dir, topLevel, rev, path string) ([]byte, error) {
+	relPath, err := filepath.Rel(topLevel, path)
+	if err != nil {
+		return nil, err
+	}
+	return runGit(dir, "show", rev+":"+filepath.ToSlash(relPath))
+}
+
+func gitRemovedGoFiles(dir, rev string, paths []string, renamedPaths util.Set[string]) (map[string][]byte, error) {
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	removedFiles := make(map[string][]byte)
+	for _, path := range paths {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			continue
+		}
+		// Renamed files already declare everything under their new paths, loading both would only make duplicates.
+		if renamedPaths.Has(path) {
+			continue
+		}
+
+		content, err := gitShow(dir, topLevel, rev, path)
+		if err != nil {
+			return nil, err
+		}
+		removedFiles[path] = content
+	}
+	return removedFiles, nil
+}
+
+func gitBaseGoFiles(dir, rev string, changes []fileChange) (map[string][]byte, error) {
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	baseFiles := make(map[string][]byte)
+	for _, change := range changes {
+		if filepath.Ext(change.Path) != ".go" {
+			continue
+		}
+
+		var basePath string
+		switch change.Status {
+		case 'M':
+			basePath = change.Path
+		case 'R':
+			basePath = change.OldPath
+		default:
+			continue
+		}
+
+		content, err := gitShow(dir, topLevel, rev, basePath)
+		if err != nil {
+			return nil, err
+		}
+		baseFiles[change.Path] = content
+	}
+	return baseFiles, nil
+}
+
+// gitModuleDiff compares the requirements of every module whose go.mod or go.sum has changed, which covers every
+// module of a workspace. It returns false when none has changed.
+func gitModuleDiff(dir string, revs revisions, changes []fileChange) (selectivetesting.ModuleDiff, bool, error) {
+	moduleDirs := util.NewSet[string]()
+	for _, change := range changes {
+		for _, path := range []string{change.Path, change.OldPath} {
+			if base := filepath.Base(path); base == "go.mod" || base == "go.sum" {
+				moduleDirs.Add(filepath.Dir(path))
+			}
+		}
+	}
+	if moduleDirs.Len() == 0 {
+		return selectivetesting.ModuleDiff{}, false, nil
+	}
+
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		return selectivetesting.ModuleDiff{}, false, err
+	}
+
+	changedModules := util.NewSet[string]()
+	toolchainChanged := false
+	for moduleDir := range moduleDirs {
+		contents := make([][]byte, 0, 4)
+		for _, path := range []string{filepath.Join(moduleDir, "go.mod"), filepath.Join(moduleDir, "go.sum")} {
+			base, err := gitShowIfExists(dir, topLevel, revs.Base, path)
+			if err != nil {
+				return selectivetesting.ModuleDiff{}, false, err
+			}
+
+			var head []byte
+			if revs.Head != "" {
+				head, err = gitShowIfExists(dir, topLevel, revs.Head, path)
+			} else {
+				head, err = os.ReadFile(path)
+				if os.IsNotExist(err) {
+					head, err = nil, nil
+				}
+			}
+			if err != nil {
+				return selectivetesting.ModuleDiff{}, false, err
+			}
+
+			contents = append(contents, base, head)
+		}
+
+		diff, err := selectivetesting.DiffModules(contents[0], contents[1], contents[2], contents[3])
+		if err != nil {
+			return selectivetesting.ModuleDiff{}, false, err
+		}
+		changedModules.Add(diff.ChangedModules...)
+		toolchainChanged = toolchainChanged || diff.ToolchainChanged
+	}
+
+	return selectivetesting.ModuleDiff{
+		ChangedModules:   changedModules.ToSlice(),
+		ToolchainChanged: toolchainChanged,
+	}, true, nil
+}
+
+func gitShowIfExists(dir, topLevel, rev, path string) ([]byte, error) {
+	relPath, err := filepath.Rel(topLevel, path)
+	if err != nil {
+		return nil, err
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	// Only a path missing from a valid revision stands for a missing file, while other errors are still reported.
+	if _, err := runGit(dir, "rev-parse", "--verify", rev+"^{commit}"); err != nil {
+		return nil, err
+	}
+	out, err := runGit(dir, "ls-tree", "--full-tree", "--name-only", rev, "--", relPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+	return runGit(dir, "show", rev+":"+relPath)
+}
+
+// renamedPathsOf returns the old paths of the renamed files.
+func renamedPathsOf(changes []fileChange) util.Set[string] {
+	renamedPaths := util.NewSet[string]()
+	for _, change := range changes {
+		if change.Status == 'R' {
+			renamedPaths.Add(change.OldPath)
+		}
+	}
+	return renamedPaths
+}
+
+func notablePathsOf(changes []fileChange) []string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+		// The old path of a rename is effectively deleted.
+		if change.Status == 'R' {
+			paths = append(paths, change.OldPath)
+		}
+	}
+	return paths
+}
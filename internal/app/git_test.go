@@ -0,0 +1,41 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+)
+
+func TestGitShowIfExists(t *stdtesting.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "go.mod"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	topLevel, err := gitTopLevel(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := gitShowIfExists(dir, topLevel, "HEAD", filepath.Join(topLevel, "go.mod"))
+	if err != nil || string(content) != "module example.com/m\n" {
+		t.Errorf("existing file: got %q, %v", content, err)
+	}
+
+	content, err = gitShowIfExists(dir, topLevel, "HEAD", filepath.Join(topLevel, "go.sum"))
+	if err != nil || content != nil {
+		t.Errorf("missing file: got %q, %v, want nil", content, err)
+	}
+
+	if _, err := gitShowIfExists(dir, topLevel, "nosuchrev", filepath.Join(topLevel, "go.mod")); err == nil {
+		t.Errorf("invalid revision: got no error")
+	}
+}
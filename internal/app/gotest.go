@@ -22,7 +22,7 @@ func (errs multiError) Error() string {
 	return strings.Join(msgs, "\n")
 }
 
-func runTests(moduleDir, args string, parallel int, testedPkgs []*testedPackage) error {
+func runTests(moduleDir, args string, parallel int, testedPkgs []*testedPackage, buildFlags, env []string) error {
 	if parallel < 1 {
 		parallel = 1
 	}
@@ -44,36 +44,40 @@ func runTests(moduleDir, args string, parallel int, testedPkgs []*testedPackage)
 				wg.Done()
 			}()
 
-			cmd := exec.Command("go", "test", testedPkg.PkgPath, "-run", testedPkg.RunRegex, args)
-			cmd.Dir = moduleDir
-
-			stderrBuf := &bytes.Buffer{}
-			cmd.Stderr = stderrBuf
-
-			stdoutBuf := &bytes.Buffer{}
-			cmd.Stdout = stdoutBuf
-
-			if err := cmd.Run(); err != nil {
-				runErrsMut.Lock()
-				runErrs = append(runErrs, err)
-				runErrsMut.Unlock()
-
-				switch err.(type) {
-				case *exec.ExitError:
-					break // Do not return and let it print.
-				case *exec.Error:
-					return
-				default:
-					return
+			for _, cmdArgs := range goTestArgsOf(testedPkg, buildFlags) {
+				cmd := exec.Command("go", append(cmdArgs, args)...)
+				cmd.Dir = moduleDir
+				if len(env) > 0 {
+					cmd.Env = append(os.Environ(), env...)
 				}
-			}
 
-			outMut.Lock()
-			defer outMut.Unlock()
+				stderrBuf := &bytes.Buffer{}
+				cmd.Stderr = stderrBuf
+
+				stdoutBuf := &bytes.Buffer{}
+				cmd.Stdout = stdoutBuf
+
+				if err := cmd.Run(); err != nil {
+					runErrsMut.Lock()
+					runErrs = append(runErrs, err)
+					runErrsMut.Unlock()
+
+					switch err.(type) {
+					case *exec.ExitError:
+						break // Do not return and let it print.
+					case *exec.Error:
+						return
+					default:
+						return
+					}
+				}
 
-			fmt.Fprintln(os.Stdout, "cmd:", cmd.String())
-			_, _ = io.Copy(os.Stderr, stderrBuf)
-			_, _ = io.Copy(os.Stdout, stdoutBuf)
+				outMut.Lock()
+				fmt.Fprintln(os.Stdout, "cmd:", cmd.String())
+				_, _ = io.Copy(os.Stderr, stderrBuf)
+				_, _ = io.Copy(os.Stdout, stdoutBuf)
+				outMut.Unlock()
+			}
 		}(testedPkg)
 	}
 	wg.Wait()
@@ -83,3 +87,31 @@ func runTests(moduleDir, args string, parallel int, testedPkgs []*testedPackage)
 	}
 	return nil
 }
+
+// goTestArgsOf returns the arguments of each go test run of the tested package. Tests with selected subtests are run
+// separately, as the subtest part of -run applies to every test matched.
+func goTestArgsOf(testedPkg *testedPackage, buildFlags []string) [][]string {
+	testArgs := func(args ...string) []string {
+		cmdArgs := append([]string{"test"}, buildFlags...)
+		return append(append(cmdArgs, testedPkg.PkgPath), args...)
+	}
+
+	runs := make([][]string, 0, 1+len(testedPkg.SubtestRunRegexes))
+	if testedPkg.RunRegex != "^$" || testedPkg.BenchRegex != "" || len(testedPkg.SubtestRunRegexes) == 0 {
+		cmdArgs := testArgs("-run", testedPkg.RunRegex)
+		if testedPkg.BenchRegex != "" {
+			cmdArgs = append(cmdArgs, "-bench", testedPkg.BenchRegex)
+		}
+		if testedPkg.TestifyRegex != "" {
+			cmdArgs = append(cmdArgs, "-testify.m", testedPkg.TestifyRegex)
+		}
+		if testedPkg.GinkgoFocus != "" {
+			cmdArgs = append(cmdArgs, "-ginkgo.focus", testedPkg.GinkgoFocus)
+		}
+		runs = append(runs, cmdArgs)
+	}
+	for _, runRegex := range testedPkg.SubtestRunRegexes {
+		runs = append(runs, testArgs("-run", runRegex))
+	}
+	return runs
+}
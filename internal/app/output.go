@@ -19,44 +19,134 @@ type testedPackageGroup struct {
 
 type testedPackage struct {
 	PkgPath         string   `json:"pkgPath"`
+	ModulePath      string   `json:"modulePath"`
 	RelativePkgPath string   `json:"relativePkgPath"`
 	HasNotable      bool     `json:"hasNotable"`
+	ByTestMain      bool     `json:"byTestMain"`
 	TestNames       []string `json:"testNames"`
-	RunRegex        string   `json:"runRegex"`
+	BenchmarkNames  []string `json:"benchmarkNames"`
+	FuzzNames       []string `json:"fuzzNames"`
+	ExampleNames    []string `json:"exampleNames"`
+	// Selected testify suite methods by the tests running their suites.
+	SuiteMethods map[string][]string `json:"suiteMethods"`
+	// Texts of the selected Ginkgo containers and specs by the tests running them.
+	GinkgoNodes map[string][]string `json:"ginkgoNodes"`
+	// Rewritten names of the selected subtests by their parent tests.
+	Subtests     map[string][]string `json:"subtests"`
+	RunRegex     string              `json:"runRegex"`
+	BenchRegex   string              `json:"benchRegex"`
+	FuzzRegex    string              `json:"fuzzRegex"`
+	TestifyRegex string              `json:"testifyRegex"`
+	GinkgoFocus  string              `json:"ginkgoFocus"`
+	// Regexes for -run of the tests with selected subtests, each to be run separately from RunRegex.
+	SubtestRunRegexes []string `json:"subtestRunRegexes"`
+	// Changed seed corpus files by the fuzz targets using them, relative to the package directory.
+	FuzzCorpora map[string][]string `json:"fuzzCorpora"`
+	// Shortest usage chains from a changed object to each selected test, "*" for those selecting every test.
+	Reasons map[string][]hop `json:"reasons"`
+}
+
+type hop struct {
+	ObjName string `json:"objName"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// profileTesting is the selection of a build profile, to be run with its build flags and environment variables.
+type profileTesting struct {
+	Name       string                `json:"name"`
+	BuildFlags []string              `json:"buildFlags"`
+	Env        []string              `json:"env"`
+	Groups     []*testedPackageGroup `json:"groups"`
 }
 
 type testing struct {
 	UniqueTestCount int                   `json:"uniqueTestCount"`
 	Groups          []*testedPackageGroup `json:"groups"`
+	Profiles        []*profileTesting     `json:"profiles,omitempty"`
 }
 
-func cleanTestedPkgs(basePkg string, crudeTestedPkgs map[string]*selectivetesting.TestedPackage) []*testedPackage {
+func cleanTestedPkgs(crudeTestedPkgs map[string]*selectivetesting.TestedPackage) []*testedPackage {
 	testedPkgs := make([]*testedPackage, 0, len(crudeTestedPkgs))
 	for pkgPath, tp := range crudeTestedPkgs {
-		testNames := tp.Names.ToSlice()
-		sort.Strings(testNames)
-
-		runRegex := "^.*"
-		if !tp.Names.Has("*") {
-			sanitizedTestNames := make([]string, 0, len(testNames))
-			for _, testName := range testNames {
-				sanitizedTestNames = append(sanitizedTestNames, regexp.QuoteMeta(testName))
+		testNames := sortedNames(tp.Names)
+		fuzzNames := sortedNames(tp.Fuzzes)
+		exampleNames := sortedNames(tp.Examples)
+		benchmarkNames := sortedNames(tp.Benchmarks)
+
+		// Testify applies the same method filter to every suite of the test binary.
+		suiteMethods := make(map[string][]string, len(tp.SuiteMethods))
+		allSuiteMethods := util.NewSet[string]()
+		for testName, methodNames := range tp.SuiteMethods {
+			suiteMethods[testName] = sortedNames(methodNames)
+			allSuiteMethods.AddFrom(methodNames)
+		}
+
+		ginkgoNodes := make(map[string][]string, len(tp.GinkgoNodes))
+		allGinkgoNodes := util.NewSet[selectivetesting.GinkgoNode]()
+		for testName, nodes := range tp.GinkgoNodes {
+			texts := make([]string, 0, nodes.Len())
+			for node := range nodes {
+				texts = append(texts, node.Text)
+			}
+			sort.Strings(texts)
+			ginkgoNodes[testName] = texts
+			allGinkgoNodes.AddFrom(nodes)
+		}
+
+		subtests := make(map[string][]string, len(tp.Subtests))
+		subtestRunRegexes := make([]string, 0, len(tp.Subtests))
+		for testName, subtestNames := range tp.Subtests {
+			subtests[testName] = sortedNames(subtestNames)
+			subtestRunRegexes = append(subtestRunRegexes, "^"+regexp.QuoteMeta(testName)+"$/"+subtestNamesRegex(subtests[testName]))
+		}
+		sort.Strings(subtestRunRegexes)
+
+		fuzzCorpora := make(map[string][]string, len(tp.FuzzCorpora))
+		for fuzzName, corpusFiles := range tp.FuzzCorpora {
+			fuzzCorpora[fuzzName] = sortedNames(corpusFiles)
+		}
+
+		reasons := make(map[string][]hop, len(tp.Reasons))
+		for testName, chain := range tp.Reasons {
+			hops := make([]hop, 0, len(chain))
+			for _, h := range chain {
+				hops = append(hops, hop{ObjName: h.ObjName, File: h.FileName, Line: h.StartLine})
 			}
+			reasons[testName] = hops
+		}
 
-			regexPiece := strings.Join(sanitizedTestNames, "|")
-			if len(sanitizedTestNames) == 1 {
-				runRegex = "^" + regexPiece + "$"
-			} else {
-				runRegex = "^(" + regexPiece + ")$"
+		// Tests with selected subtests are left to their own runs.
+		mainTestNames := make([]string, 0, len(testNames))
+		for _, testName := range testNames {
+			if _, ok := tp.Subtests[testName]; !ok {
+				mainTestNames = append(mainTestNames, testName)
 			}
 		}
 
 		testedPkgs = append(testedPkgs, &testedPackage{
 			PkgPath:         pkgPath,
-			RelativePkgPath: util.RelatifyPath(basePkg, pkgPath),
+			ModulePath:      tp.Module,
+			RelativePkgPath: util.RelatifyPath(tp.Module, pkgPath),
 			HasNotable:      tp.HasNotable,
+			ByTestMain:      tp.ByTestMain,
 			TestNames:       testNames,
-			RunRegex:        runRegex,
+			BenchmarkNames:  benchmarkNames,
+			FuzzNames:       fuzzNames,
+			ExampleNames:    exampleNames,
+			SuiteMethods:    suiteMethods,
+			GinkgoNodes:     ginkgoNodes,
+			Subtests:        subtests,
+			// Fuzz targets and examples are run along with the tests, the former against their seed corpus.
+			RunRegex:     runRegexOf(map[string][]string{"Test": mainTestNames, "Fuzz": fuzzNames, "Example": exampleNames}),
+			BenchRegex:   namesRegex(benchmarkNames),
+			FuzzRegex:    namesRegex(fuzzNames),
+			TestifyRegex: namesRegex(sortedNames(allSuiteMethods)),
+			GinkgoFocus:  ginkgoFocusOf(allGinkgoNodes),
+
+			SubtestRunRegexes: subtestRunRegexes,
+			FuzzCorpora:       fuzzCorpora,
+			Reasons:           reasons,
 		})
 	}
 
@@ -67,6 +157,87 @@ func cleanTestedPkgs(basePkg string, crudeTestedPkgs map[string]*selectivetestin
 	return testedPkgs
 }
 
+// ginkgoFocusOf returns a regex matching the full texts of the specs within the given nodes, or an empty string when
+// there are none.
+func ginkgoFocusOf(nodes util.Set[selectivetesting.GinkgoNode]) string {
+	pieces := make([]string, 0, nodes.Len())
+	for node := range nodes {
+		// Texts of the specs within a container follow its own after a space.
+		end := "$"
+		if node.Container {
+			end = "( |$)"
+		}
+		pieces = append(pieces, "^"+regexp.QuoteMeta(node.Text)+end)
+	}
+	sort.Strings(pieces)
+	return strings.Join(pieces, "|")
+}
+
+func sortedNames(names util.Set[string]) []string {
+	sorted := names.ToSlice()
+	sort.Strings(sorted)
+	return sorted
+}
+
+// runRegexOf combines the names of each kind by their prefix into a single regex for -run.
+func runRegexOf(namesByPrefix map[string][]string) string {
+	prefixes := make([]string, 0, len(namesByPrefix))
+	for prefix := range namesByPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	pieces := make([]string, 0)
+	allWildcards := true
+	for _, prefix := range prefixes {
+		names := namesByPrefix[prefix]
+		// Matching everything would run the kinds left out as well.
+		if len(names) == 0 {
+			allWildcards = false
+			continue
+		}
+		if len(names) == 1 && names[0] == "*" {
+			pieces = append(pieces, prefix+".*")
+			continue
+		}
+		allWildcards = false
+		for _, name := range names {
+			pieces = append(pieces, regexp.QuoteMeta(name))
+		}
+	}
+
+	switch {
+	case len(pieces) == 0:
+		return "^$"
+	case allWildcards:
+		return "^.*"
+	case len(pieces) == 1:
+		return "^" + pieces[0] + "$"
+	}
+	return "^(" + strings.Join(pieces, "|") + ")$"
+}
+
+// namesRegex returns a regex matching exactly the given names, or an empty string when there are none.
+func namesRegex(names []string) string {
+	switch {
+	case len(names) == 0:
+		return ""
+	case len(names) == 1 && names[0] == "*":
+		return "^.*"
+	}
+	return runRegexOf(map[string][]string{"": names})
+}
+
+// subtestNamesRegex matches the subtests by name, along with the #01 suffixes the testing package gives to subtests
+// sharing the name of an earlier one, such as those run in a loop.
+func subtestNamesRegex(names []string) string {
+	quotedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		quotedNames = append(quotedNames, regexp.QuoteMeta(name))
+	}
+	return "^(" + strings.Join(quotedNames, "|") + `)(#[0-9]+)?$`
+}
+
 func addToGroup(groups map[string]*testedPackageGroup, name string, testedPkg *testedPackage) {
 	group := util.MapGetOrCreate(groups, name, func() *testedPackageGroup {
 		return &testedPackageGroup{Name: name}
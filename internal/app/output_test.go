@@ -0,0 +1,24 @@
+package app
+
+import (
+	"regexp"
+	// The package has a testing type of its own.
+	stdtesting "testing"
+)
+
+func TestSubtestNamesRegex(t *stdtesting.T) {
+	re := regexp.MustCompile(subtestNamesRegex([]string{"a_b", "c.d"}))
+	for name, want := range map[string]bool{
+		"a_b":    true,
+		"a_b#01": true,
+		"c.d#12": true,
+		"cxd":    false,
+		"a_b#":   false,
+		"a_bc":   false,
+		"xa_b":   false,
+	} {
+		if got := re.MatchString(name); got != want {
+			t.Errorf("%s: got %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ezraisw/go-selectivetesting"
+)
+
+type stats struct {
+	DefinitionCount int                `json:"definitionCount"`
+	EdgeCount       int                `json:"edgeCount"`
+	TestCounts      map[string]int     `json:"testCounts"`
+	TopObjects      []blastRadiusEntry `json:"topObjects"`
+	TopFiles        []blastRadiusEntry `json:"topFiles"`
+}
+
+type blastRadiusEntry struct {
+	ObjName   string `json:"objName,omitempty"`
+	PkgPath   string `json:"pkgPath"`
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	TestCount int    `json:"testCount"`
+}
+
+// runStats prints the objects and files selecting the most tests when changed, along with metrics of the graph.
+func runStats(args []string) error {
+	var (
+		top        int
+		jsonOutput bool
+	)
+
+	fs := flag.NewFlagSet(os.Args[0]+" stats", flag.ExitOnError)
+	fs.IntVar(&top, "top", 10, "Number of objects and files to list.")
+	fs.BoolVar(&jsonOutput, "json", false, "Whether to output json instead of text.")
+
+	cfg, _, err := parseArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	fa, err := loadFileAnalyzer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	graphStats := fa.GraphStats()
+	s := stats{
+		DefinitionCount: graphStats.DefinitionCount,
+		EdgeCount:       graphStats.EdgeCount,
+		TestCounts:      graphStats.TestCounts,
+		TopObjects:      blastRadiusEntries(fa.BlastRadii(), top),
+		TopFiles:        blastRadiusEntries(fa.FileBlastRadii(), top),
+	}
+	if jsonOutput {
+		return jsonTo(os.Stdout, cfg.PrettyOutput, s)
+	}
+	return writeStats(os.Stdout, s)
+}
+
+func blastRadiusEntries(radii []selectivetesting.BlastRadius, top int) []blastRadiusEntry {
+	if top >= 0 && len(radii) > top {
+		radii = radii[:top]
+	}
+	entries := make([]blastRadiusEntry, 0, len(radii))
+	for _, radius := range radii {
+		entries = append(entries, blastRadiusEntry{
+			ObjName:   radius.ObjName,
+			PkgPath:   radius.PkgPath,
+			File:      radius.FileName,
+			Line:      radius.StartLine,
+			TestCount: radius.TestCount,
+		})
+	}
+	return entries
+}
+
+func writeStats(out io.Writer, s stats) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Definitions:\t%d\n", s.DefinitionCount)
+	fmt.Fprintf(w, "Edges:\t%d\n", s.EdgeCount)
+
+	fmt.Fprintln(w, "\nTESTS\tOBJECT\tLOCATION")
+	for _, entry := range s.TopObjects {
+		fmt.Fprintf(w, "%d\t%s\t%s:%d\n", entry.TestCount, entry.ObjName, entry.File, entry.Line)
+	}
+
+	fmt.Fprintln(w, "\nTESTS\tFILE")
+	for _, entry := range s.TopFiles {
+		fmt.Fprintf(w, "%d\t%s\n", entry.TestCount, entry.File)
+	}
+
+	pkgPaths := make([]string, 0, len(s.TestCounts))
+	for pkgPath := range s.TestCounts {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	fmt.Fprintln(w, "\nTESTS\tPACKAGE")
+	for _, pkgPath := range pkgPaths {
+		fmt.Fprintf(w, "%d\t%s\n", s.TestCounts[pkgPath], pkgPath)
+	}
+
+	return w.Flush()
+}
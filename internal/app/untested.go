@@ -0,0 +1,81 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ezraisw/go-selectivetesting"
+)
+
+type untestedReport struct {
+	Packages []*untestedPackage `json:"packages"`
+}
+
+type untestedPackage struct {
+	PkgPath string           `json:"pkgPath"`
+	Objects []untestedObject `json:"objects"`
+}
+
+type untestedObject struct {
+	ObjName string `json:"objName"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// runUntested prints the definitions that no test reaches, grouped by package.
+func runUntested(args []string) error {
+	var (
+		changedOnly bool
+		jsonOutput  bool
+	)
+
+	fs := flag.NewFlagSet(os.Args[0]+" untested", flag.ExitOnError)
+	fs.BoolVar(&changedOnly, "changedonly", false, "Whether to only report definitions changed in the input files or git revisions.")
+	fs.BoolVar(&jsonOutput, "json", false, "Whether to output json instead of text.")
+
+	cfg, inputPaths, err := parseArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	fa, err := loadFileAnalyzer(cfg, inputPaths)
+	if err != nil {
+		return err
+	}
+
+	report := groupUntested(fa.UntestedDefinitions(changedOnly))
+	if jsonOutput {
+		return jsonTo(os.Stdout, cfg.PrettyOutput, report)
+	}
+	writeUntested(os.Stdout, report)
+	return nil
+}
+
+// groupUntested groups the definitions, which are sorted by package path.
+func groupUntested(defs []selectivetesting.UntestedDefinition) untestedReport {
+	report := untestedReport{Packages: make([]*untestedPackage, 0)}
+
+	var pkg *untestedPackage
+	for _, def := range defs {
+		if pkg == nil || pkg.PkgPath != def.PkgPath {
+			pkg = &untestedPackage{PkgPath: def.PkgPath, Objects: make([]untestedObject, 0)}
+			report.Packages = append(report.Packages, pkg)
+		}
+		pkg.Objects = append(pkg.Objects, untestedObject{
+			ObjName: def.ObjName,
+			File:    def.FileName,
+			Line:    def.StartLine,
+		})
+	}
+	return report
+}
+
+func writeUntested(w io.Writer, report untestedReport) {
+	for _, pkg := range report.Packages {
+		fmt.Fprintln(w, pkg.PkgPath)
+		for _, obj := range pkg.Objects {
+			fmt.Fprintf(w, "  %s (%s:%d)\n", obj.ObjName, obj.File, obj.Line)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package selectivetesting
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/mod/modfile"
+)
+
+type ModuleDiff struct {
+	// Paths of modules whose selected version, replacement or checksum changed.
+	ChangedModules []string
+	// Whether the go directive or the toolchain line changed.
+	ToolchainChanged bool
+}
+
+// DiffModules compares the requirements of two revisions of go.mod and go.sum. Any of the contents can be nil
+// when the file does not exist in that revision.
+func DiffModules(baseGoMod, headGoMod, baseGoSum, headGoSum []byte) (ModuleDiff, error) {
+	baseModFile, err := parseModFile(baseGoMod)
+	if err != nil {
+		return ModuleDiff{}, err
+	}
+	headModFile, err := parseModFile(headGoMod)
+	if err != nil {
+		return ModuleDiff{}, err
+	}
+
+	changed := util.NewSet[string]()
+	addDiff(changed, requiredVersions(baseModFile), requiredVersions(headModFile))
+	addDiff(changed, replacements(baseModFile), replacements(headModFile))
+	addDiff(changed, sumHashes(baseGoSum), sumHashes(headGoSum))
+
+	return ModuleDiff{
+		ChangedModules:   changed.ToSlice(),
+		ToolchainChanged: goVersion(baseModFile) != goVersion(headModFile) || toolchain(baseModFile) != toolchain(headModFile),
+	}, nil
+}
+
+func parseModFile(content []byte) (*modfile.File, error) {
+	if content == nil {
+		return &modfile.File{}, nil
+	}
+	// Strict parsing fails on directives added by newer go versions, so replace and toolchain lines, which lax parsing
+	// leaves out, are read from the syntax tree instead.
+	return modfile.ParseLax("go.mod", content, nil)
+}
+
+func requiredVersions(f *modfile.File) map[string]string {
+	versions := make(map[string]string, len(f.Require))
+	for _, req := range f.Require {
+		versions[req.Mod.Path] = req.Mod.Version
+	}
+	return versions
+}
+
+func replacements(f *modfile.File) map[string]string {
+	replaced := make(map[string]string)
+	// The old path is followed by an optional version, the arrow, and the new path with an optional version.
+	for _, args := range directiveArgs(f, "replace") {
+		if len(args) == 0 {
+			continue
+		}
+		replaced[args[0]] += strings.Join(args[1:], " ") + ";"
+	}
+	return replaced
+}
+
+func sumHashes(content []byte) map[string]string {
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Only the hash of the module content matters, not the one of its go.mod.
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		hashes[fields[0]] += fields[1] + " " + fields[2] + ";"
+	}
+	return hashes
+}
+
+func addDiff(changed util.Set[string], base, head map[string]string) {
+	for path, v := range base {
+		if head[path] != v {
+			changed.Add(path)
+		}
+	}
+	for path, v := range head {
+		if base[path] != v {
+			changed.Add(path)
+		}
+	}
+}
+
+func goVersion(f *modfile.File) string {
+	if f.Go == nil {
+		return ""
+	}
+	return f.Go.Version
+}
+
+func toolchain(f *modfile.File) string {
+	for _, args := range directiveArgs(f, "toolchain") {
+		if len(args) > 0 {
+			return args[0]
+		}
+	}
+	return ""
+}
+
+// directiveArgs returns the arguments of every line of the directive, whether on its own or within a block.
+func directiveArgs(f *modfile.File, verb string) [][]string {
+	if f.Syntax == nil {
+		return nil
+	}
+
+	args := make([][]string, 0)
+	for _, stmt := range f.Syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			if len(stmt.Token) > 0 && stmt.Token[0] == verb {
+				args = append(args, stmt.Token[1:])
+			}
+		case *modfile.LineBlock:
+			if len(stmt.Token) > 0 && stmt.Token[0] == verb {
+				for _, line := range stmt.Line {
+					args = append(args, line.Token)
+				}
+			}
+		}
+	}
+	return args
+}
+
+func isWithinModule(modulePath, pkgPath string) bool {
+	return pkgPath == modulePath || strings.HasPrefix(pkgPath, modulePath+"/")
+}
@@ -0,0 +1,140 @@
+package selectivetesting
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffModules(t *testing.T) {
+	const goMod = `module example.com/m
+
+go 1.21
+
+require (
+	github.com/a/a v1.0.0
+	github.com/b/b v1.0.0
+)
+`
+	const goSum = `github.com/a/a v1.0.0 h1:aaa=
+github.com/a/a v1.0.0/go.mod h1:amod=
+github.com/b/b v1.0.0 h1:bbb=
+github.com/b/b v1.0.0/go.mod h1:bmod=
+`
+
+	for _, tc := range []struct {
+		name                 string
+		baseGoMod, headGoMod string
+		baseGoSum, headGoSum string
+		wantModules          []string
+		wantToolchainChanged bool
+	}{
+		{
+			name:      "unchanged",
+			baseGoMod: goMod, headGoMod: goMod,
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules: []string{},
+		},
+		{
+			name:      "version bumped",
+			baseGoMod: goMod, headGoMod: strings.ReplaceAll(goMod, "github.com/a/a v1.0.0", "github.com/a/a v1.1.0"),
+			baseGoSum: goSum, headGoSum: strings.ReplaceAll(goSum, "github.com/a/a v1.0.0", "github.com/a/a v1.1.0"),
+			wantModules: []string{"github.com/a/a"},
+		},
+		{
+			name:      "added",
+			baseGoMod: goMod, headGoMod: goMod + "\nrequire github.com/c/c v1.0.0\n",
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules: []string{"github.com/c/c"},
+		},
+		{
+			name:      "removed",
+			baseGoMod: goMod, headGoMod: strings.ReplaceAll(goMod, "\tgithub.com/b/b v1.0.0\n", ""),
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules: []string{"github.com/b/b"},
+		},
+		{
+			name:      "replaced",
+			baseGoMod: goMod, headGoMod: goMod + "\nreplace github.com/b/b => ../b\n",
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules: []string{"github.com/b/b"},
+		},
+		{
+			name:      "content hash changed",
+			baseGoMod: goMod, headGoMod: goMod,
+			baseGoSum: goSum, headGoSum: strings.ReplaceAll(goSum, "h1:bbb=", "h1:ccc="),
+			wantModules: []string{"github.com/b/b"},
+		},
+		{
+			name:      "go.mod hash changed",
+			baseGoMod: goMod, headGoMod: goMod,
+			baseGoSum: goSum, headGoSum: strings.ReplaceAll(goSum, "h1:bmod=", "h1:cmod="),
+			wantModules: []string{},
+		},
+		{
+			name:      "go directive changed",
+			baseGoMod: goMod, headGoMod: strings.ReplaceAll(goMod, "go 1.21", "go 1.22"),
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules:          []string{},
+			wantToolchainChanged: true,
+		},
+		{
+			name:      "toolchain added",
+			baseGoMod: goMod, headGoMod: goMod + "\ntoolchain go1.22.1\n",
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules:          []string{},
+			wantToolchainChanged: true,
+		},
+		{
+			name:      "replace block",
+			baseGoMod: goMod + "\nreplace (\n\tgithub.com/a/a => ../a\n\tgithub.com/b/b v1.0.0 => ../b\n)\n",
+			headGoMod: goMod + "\nreplace (\n\tgithub.com/a/a => ../a\n\tgithub.com/b/b v1.0.0 => ../c\n)\n",
+			baseGoSum: goSum, headGoSum: goSum,
+			wantModules: []string{"github.com/b/b"},
+		},
+		{
+			name:      "unknown directives",
+			baseGoMod: goMod + "\ntool example.com/tool\n\nignore ./node_modules\n",
+			headGoMod: strings.ReplaceAll(goMod, "github.com/a/a v1.0.0", "github.com/a/a v1.1.0") +
+				"\ntool example.com/tool\n\nignore ./node_modules\n\nfuture directive\n",
+			baseGoSum:   goSum,
+			headGoSum:   goSum,
+			wantModules: []string{"github.com/a/a"},
+		},
+		{
+			name:                 "no base",
+			headGoMod:            goMod,
+			headGoSum:            goSum,
+			wantModules:          []string{"github.com/a/a", "github.com/b/b"},
+			wantToolchainChanged: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := DiffModules(bytesOrNil(tc.baseGoMod), bytesOrNil(tc.headGoMod), bytesOrNil(tc.baseGoSum), bytesOrNil(tc.headGoSum))
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(diff.ChangedModules)
+			if !slices.Equal(diff.ChangedModules, tc.wantModules) {
+				t.Errorf("got changed modules %q, want %q", diff.ChangedModules, tc.wantModules)
+			}
+			if diff.ToolchainChanged != tc.wantToolchainChanged {
+				t.Errorf("got toolchain changed %t, want %t", diff.ToolchainChanged, tc.wantToolchainChanged)
+			}
+		})
+	}
+}
+
+func TestDiffModulesInvalid(t *testing.T) {
+	if _, err := DiffModules(nil, []byte("module\nrequire (\n"), nil, nil); err == nil {
+		t.Errorf("expected an error for an invalid go.mod")
+	}
+}
+
+// bytesOrNil stands for a file missing from the revision with an empty string.
+func bytesOrNil(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
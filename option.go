@@ -37,3 +37,51 @@ func WithTestAll(testAll bool) Option {
 		fa.testAll = testAll
 	}
 }
+
+func WithNotableRanges(ranges ...NotableRange) Option {
+	return func(fa *FileAnalyzer) {
+		for _, r := range ranges {
+			fa.notableFileNames.Add(r.FileName)
+			fa.notableRanges[r.FileName] = append(fa.notableRanges[r.FileName], r)
+		}
+	}
+}
+
+func WithRemovedFiles(removedFiles map[string][]byte) Option {
+	return func(fa *FileAnalyzer) {
+		for fileName := range removedFiles {
+			fa.notableFileNames.Add(fileName)
+		}
+		fa.removedFiles = removedFiles
+	}
+}
+
+func WithSemanticDiff(baseFiles map[string][]byte) Option {
+	return func(fa *FileAnalyzer) {
+		fa.baseFiles = baseFiles
+	}
+}
+
+func WithCachePath(cachePath string) Option {
+	return func(fa *FileAnalyzer) {
+		fa.cachePath = cachePath
+	}
+}
+
+func WithChangedModules(modulePaths ...string) Option {
+	return func(fa *FileAnalyzer) {
+		fa.changedModules = modulePaths
+	}
+}
+
+func WithBasePkgs(basePkgs ...string) Option {
+	return func(fa *FileAnalyzer) {
+		fa.basePkgs = append(fa.basePkgs, basePkgs...)
+	}
+}
+
+func WithBuildProfiles(profiles ...BuildProfile) Option {
+	return func(fa *FileAnalyzer) {
+		fa.profiles = profiles
+	}
+}
@@ -0,0 +1,171 @@
+package selectivetesting
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+// BuildProfile is a build configuration the packages are loaded under, such as a set of build tags or a target
+// platform.
+type BuildProfile struct {
+	Name string
+	// Build flags on top of those of the analyzer, such as -tags=integration.
+	BuildFlags []string
+	// Environment variables on top of the current ones, such as GOOS=windows.
+	Env []string
+}
+
+// buildProfiles returns the configured build profiles, or a single unnamed one using the build flags as they are.
+func (fa *FileAnalyzer) buildProfiles() []BuildProfile {
+	if len(fa.profiles) == 0 {
+		return []BuildProfile{{}}
+	}
+	return fa.profiles
+}
+
+// BuildFlagsOf returns the build flags the packages are loaded with under the profile, which the tests selected for it
+// are to be run with as well.
+func (fa *FileAnalyzer) BuildFlagsOf(profile BuildProfile) []string {
+	return mergeTags(append(slices.Clone(fa.buildFlags), profile.BuildFlags...))
+}
+
+// mergeTags combines the -tags flags into a single one in place of the first, as the go command only keeps the last.
+func mergeTags(flags []string) []string {
+	merged := make([]string, 0, len(flags))
+	tags := make([]string, 0)
+	tagsIdx := -1
+	for i := 0; i < len(flags); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(flags[i], "-"), "=")
+		if name != "-tags" && name != "tags" {
+			merged = append(merged, flags[i])
+			continue
+		}
+		if !hasValue && i+1 < len(flags) {
+			i++
+			value = flags[i]
+		}
+		if tagsIdx == -1 {
+			tagsIdx = len(merged)
+			merged = append(merged, "")
+		}
+		// Tags used to be separated by spaces.
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if tagsIdx != -1 {
+		merged[tagsIdx] = "-tags=" + strings.Join(tags, ",")
+	}
+	return merged
+}
+
+func envOf(profile BuildProfile) []string {
+	// Nil stands for the current environment.
+	if len(profile.Env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), profile.Env...)
+}
+
+// ProfileTests splits the selected tests by the build profiles compiling them. Tests compiled under several profiles
+// are selected for each of them, and packages with no selected test under a profile are left out of it.
+func (fa *FileAnalyzer) ProfileTests(testedPkgs map[string]*TestedPackage) map[string]map[string]*TestedPackage {
+	// Names of the tests compiled under each profile by package path.
+	compiledNames := make(map[string]map[string]util.Set[string])
+	for objName := range fa.testFuncs {
+		def := fa.definitions[objName]
+		for profileName := range def.profiles {
+			pkgNames := util.MapGetOrCreate(compiledNames, profileName, func() map[string]util.Set[string] {
+				return make(map[string]util.Set[string])
+			})
+			names := util.MapGetOrCreate(pkgNames, def.pkgPath, func() util.Set[string] { return util.NewSet[string]() })
+			names.Add(def.name)
+		}
+	}
+
+	profileTestedPkgs := make(map[string]map[string]*TestedPackage, len(fa.profiles))
+	for _, profile := range fa.profiles {
+		profileTestedPkgs[profile.Name] = make(map[string]*TestedPackage)
+		for pkgPath, testedPkg := range testedPkgs {
+			names := compiledNames[profile.Name][pkgPath]
+			if names.Len() == 0 {
+				continue
+			}
+			if profileTestedPkg := testedPkg.keeping(names); profileTestedPkg != nil {
+				profileTestedPkgs[profile.Name][pkgPath] = profileTestedPkg
+			}
+		}
+	}
+	return profileTestedPkgs
+}
+
+// keeping returns a copy of the tested package only selecting the given tests, "*" selecting those of the same kind,
+// or nil when none is selected.
+func (tp *TestedPackage) keeping(names util.Set[string]) *TestedPackage {
+	kept := &TestedPackage{
+		SuiteMethods: make(map[string]util.Set[string]),
+		GinkgoNodes:  make(map[string]util.Set[GinkgoNode]),
+		Subtests:     make(map[string]util.Set[string]),
+		FuzzCorpora:  make(map[string]util.Set[string]),
+		Reasons:      make(map[string][]Hop),
+		HasNotable:   tp.HasNotable,
+		Module:       tp.Module,
+		ByTestMain:   tp.ByTestMain,
+	}
+
+	empty := true
+	for _, kind := range testKinds {
+		keptNames := util.NewSet[string]()
+		for name := range *tp.namesOf(kind) {
+			if name != "*" {
+				if names.Has(name) {
+					keptNames.Add(name)
+				}
+				continue
+			}
+			for compiledName := range names {
+				if testKindOf(compiledName) == kind {
+					keptNames.Add("*")
+					break
+				}
+			}
+		}
+		*kept.namesOf(kind) = keptNames
+		empty = empty && keptNames.Len() == 0
+	}
+	if empty {
+		return nil
+	}
+
+	for name, methodNames := range tp.SuiteMethods {
+		if kept.Has(name) {
+			kept.SuiteMethods[name] = methodNames
+		}
+	}
+	for name, nodes := range tp.GinkgoNodes {
+		if kept.Has(name) {
+			kept.GinkgoNodes[name] = nodes
+		}
+	}
+	for name, subtestNames := range tp.Subtests {
+		if kept.Has(name) {
+			kept.Subtests[name] = subtestNames
+		}
+	}
+	for name, corpusFiles := range tp.FuzzCorpora {
+		if kept.Has(name) {
+			kept.FuzzCorpora[name] = corpusFiles
+		}
+	}
+	for name, chain := range tp.Reasons {
+		if name == "*" || kept.Has(name) {
+			kept.Reasons[name] = chain
+		}
+	}
+	return kept
+}
@@ -0,0 +1,105 @@
+package selectivetesting
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPerPlatformDefinitions(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"x/x_linux.go": `package x
+
+func Name() string {
+	return "linux"
+}
+`,
+		"x/x_windows.go": `package x
+
+const platform = "windows"
+
+// Name differs by platform.
+func Name() string {
+	return platform
+}
+`,
+		"x/x_test.go": `package x
+
+import "testing"
+
+func TestName(t *testing.T) {
+	_ = Name()
+}
+`,
+	})
+	profiles := []BuildProfile{
+		{Name: "linux", Env: []string{"GOOS=linux"}},
+		{Name: "windows", Env: []string{"GOOS=windows"}},
+	}
+
+	for _, tc := range []struct {
+		fileName  string
+		startLine int
+	}{
+		{fileName: "x/x_linux.go", startLine: 4},
+		{fileName: "x/x_windows.go", startLine: 7},
+	} {
+		t.Run(tc.fileName, func(t *testing.T) {
+			fileName := filepath.Join(dir, filepath.FromSlash(tc.fileName))
+			fa := loadTestModule(t, dir, nil, WithBuildProfiles(profiles...))
+
+			// Ranges outside of any definition would fall back to the whole file.
+			objNames, ok := fa.objNamesWithinRanges(fileName, []NotableRange{{
+				FileName:  fileName,
+				StartLine: tc.startLine,
+				EndLine:   tc.startLine,
+			}})
+			if !ok || len(objNames) != 1 || fa.definitions[objNames[0]].name != "Name" {
+				t.Errorf("got %v, %t, want Name", objNames, ok)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		flags []string
+		want  []string
+	}{
+		{
+			name:  "no tags",
+			flags: []string{"-race"},
+			want:  []string{"-race"},
+		},
+		{
+			name:  "single",
+			flags: []string{"-tags=a,b", "-race"},
+			want:  []string{"-tags=a,b", "-race"},
+		},
+		{
+			name:  "user and profile",
+			flags: []string{"-tags=a", "-race", "-tags=integration"},
+			want:  []string{"-tags=a,integration", "-race"},
+		},
+		{
+			name:  "separate value",
+			flags: []string{"-tags", "a b", "--tags=b,c"},
+			want:  []string{"-tags=a,b,c"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mergeTags(tc.flags); !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildFlagsOf(t *testing.T) {
+	fa := NewFileAnalyzer(testModulePath, nil, WithBuildFlags("-tags=foo", "-race"))
+	got := fa.BuildFlagsOf(BuildProfile{Name: "integration", BuildFlags: []string{"-tags=integration"}})
+	if want := []string{"-tags=foo,integration", "-race"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+package selectivetesting
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type normalizedDecl struct {
+	normalized string
+	startLine  int
+	endLine    int
+}
+
+// semanticRanges compares the top-level declarations of the base and the current revision of a file and returns the
+// ranges of declarations that have changed. It returns false when the whole file should be considered instead.
+func semanticRanges(fileName string, baseSrc []byte) ([]NotableRange, bool) {
+	headSrc, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, false
+	}
+
+	baseHeader, baseDecls, err := normalizeFile(baseSrc)
+	if err != nil {
+		return nil, false
+	}
+	headHeader, headDecls, err := normalizeFile(headSrc)
+	if err != nil {
+		return nil, false
+	}
+
+	// Package name or imports changing may change the meaning of everything.
+	if baseHeader != headHeader {
+		return nil, false
+	}
+
+	for key := range baseDecls {
+		// Removed fields change the enclosing struct type, which is still there to seed from.
+		if strings.HasPrefix(key, "field ") {
+			continue
+		}
+		if _, ok := headDecls[key]; !ok {
+			// Removed declarations have nothing left to seed from.
+			return nil, false
+		}
+	}
+
+	ranges := make([]NotableRange, 0)
+	for key, headDecl := range headDecls {
+		if baseDecl, ok := baseDecls[key]; ok && baseDecl.normalized == headDecl.normalized {
+			continue
+		}
+		ranges = append(ranges, NotableRange{
+			FileName:  fileName,
+			StartLine: headDecl.startLine,
+			EndLine:   headDecl.endLine,
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].StartLine < ranges[j].StartLine
+	})
+
+	return ranges, true
+}
+
+func normalizeFile(src []byte) (string, map[string]normalizedDecl, error) {
+	fset := token.NewFileSet()
+	// Comments are skipped by the comparison, except for directives changing the meaning of code.
+	astFile, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Build constraints decide whether the file is compiled at all.
+	constraints := make([]string, 0)
+	for _, group := range astFile.Comments {
+		if group.Pos() >= astFile.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if constraint.IsGoBuild(comment.Text) || constraint.IsPlusBuild(comment.Text) {
+				constraints = append(constraints, comment.Text)
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(astFile.Imports))
+	for _, d := range astFile.Decls {
+		decl, ok := d.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range decl.Specs {
+			spec := s.(*ast.ImportSpec)
+			normalized := normalizeNode(spec)
+			// The preamble of cgo is C code compiled along with the package.
+			if spec.Path.Value == `"C"` {
+				normalized += "\n" + spec.Doc.Text()
+				if !decl.Lparen.IsValid() {
+					normalized += "\n" + decl.Doc.Text()
+				}
+			}
+			imports = append(imports, normalized)
+		}
+	}
+	sort.Strings(imports)
+	header := strings.Join(constraints, "\n") + "\n" + astFile.Name.Name + "\n" + strings.Join(imports, "\n")
+
+	decls := make(map[string]normalizedDecl)
+	add := func(key string, node ast.Node, normalized string) {
+		// Some declarations such as init functions and blank identifiers can be repeated.
+		uniqKey := key
+		for i := 1; ; i++ {
+			if _, ok := decls[uniqKey]; !ok {
+				break
+			}
+			uniqKey = key + "#" + strconv.Itoa(i)
+		}
+		decls[uniqKey] = normalizedDecl{
+			normalized: normalized,
+			startLine:  fset.Position(node.Pos()).Line,
+			endLine:    fset.Position(node.End()).Line,
+		}
+	}
+
+	for _, d := range astFile.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			add("func "+recvTypeName(decl)+decl.Name.Name, decl, directivesOf(decl.Doc)+normalizeNode(decl))
+
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+
+			// Specs of a constant group without values repeat the previous expression, so they depend on each other.
+			var groupNormalized string
+			if decl.Tok == token.CONST && hasImplicitValues(decl) {
+				groupNormalized = normalizeNode(decl)
+			}
+
+			// Directives of ungrouped declarations belong to the declaration itself.
+			var declDirectives string
+			if !decl.Lparen.IsValid() {
+				declDirectives = directivesOf(decl.Doc)
+			}
+
+			for _, s := range decl.Specs {
+				normalized := groupNormalized
+				if normalized == "" {
+					normalized = decl.Tok.String() + normalizeNode(s)
+				}
+
+				switch spec := s.(type) {
+				case *ast.TypeSpec:
+					// Fields are compared on their own so that changing one does not change the whole type.
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						normalized = decl.Tok.String() + normalizeNode(structSkeleton(spec, st))
+						for _, field := range st.Fields.List {
+							for _, name := range field.Names {
+								add("field "+spec.Name.Name+"."+name.Name, field, normalizeNode(field))
+							}
+						}
+					}
+					add("type "+spec.Name.Name, spec, declDirectives+directivesOf(spec.Doc)+normalized)
+				case *ast.ValueSpec:
+					names := make([]string, 0, len(spec.Names))
+					for _, name := range spec.Names {
+						names = append(names, name.Name)
+					}
+					add(decl.Tok.String()+" "+strings.Join(names, ","), spec, declDirectives+directivesOf(spec.Doc)+normalized)
+				}
+			}
+		}
+	}
+
+	return header, decls, nil
+}
+
+// directivesOf returns the directives within the doc comment, such as go:embed and go:linkname, which change the
+// meaning of the declaration they are attached to.
+func directivesOf(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, comment := range doc.List {
+		if strings.HasPrefix(comment.Text, "//go:") || strings.HasPrefix(comment.Text, "//export ") {
+			b.WriteString(comment.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func recvTypeName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := decl.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+			continue
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.IndexExpr:
+			expr = e.X
+			continue
+		case *ast.IndexListExpr:
+			expr = e.X
+			continue
+		case *ast.Ident:
+			return e.Name + "."
+		}
+		return ""
+	}
+}
+
+// structSkeleton returns a copy of the type spec in which named fields are reduced to their names.
+func structSkeleton(spec *ast.TypeSpec, st *ast.StructType) *ast.TypeSpec {
+	fields := make([]*ast.Field, 0, len(st.Fields.List))
+	for _, field := range st.Fields.List {
+		// Embedded fields have no name to key them by, so they stay part of the type.
+		if len(field.Names) > 0 {
+			field = &ast.Field{Names: field.Names}
+		}
+		fields = append(fields, field)
+	}
+
+	skeleton := *spec
+	skeleton.Type = &ast.StructType{Fields: &ast.FieldList{List: fields}}
+	return &skeleton
+}
+
+func hasImplicitValues(decl *ast.GenDecl) bool {
+	for _, s := range decl.Specs {
+		if spec, ok := s.(*ast.ValueSpec); ok && len(spec.Values) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeNode serializes the structure of a node without positions, formatting and comments.
+func normalizeNode(node ast.Node) string {
+	var b strings.Builder
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			b.WriteByte(')')
+			return false
+		}
+
+		switch n.(type) {
+		case *ast.Comment, *ast.CommentGroup:
+			return false
+		}
+
+		fmt.Fprintf(&b, "(%T", n)
+
+		// Record tokens and optional children that cannot be told apart by the visited children alone.
+		switch n := n.(type) {
+		case *ast.Ident:
+			fmt.Fprintf(&b, " %s", n.Name)
+		case *ast.BasicLit:
+			fmt.Fprintf(&b, " %s %s", n.Kind, n.Value)
+		case *ast.BinaryExpr:
+			fmt.Fprintf(&b, " %s", n.Op)
+		case *ast.UnaryExpr:
+			fmt.Fprintf(&b, " %s", n.Op)
+		case *ast.AssignStmt:
+			fmt.Fprintf(&b, " %s", n.Tok)
+		case *ast.IncDecStmt:
+			fmt.Fprintf(&b, " %s", n.Tok)
+		case *ast.BranchStmt:
+			fmt.Fprintf(&b, " %s", n.Tok)
+		case *ast.GenDecl:
+			fmt.Fprintf(&b, " %s", n.Tok)
+		case *ast.ChanType:
+			fmt.Fprintf(&b, " %d", n.Dir)
+		case *ast.TypeSpec:
+			fmt.Fprintf(&b, " %t", n.Assign.IsValid())
+		case *ast.ValueSpec:
+			fmt.Fprintf(&b, " %t %d", n.Type != nil, len(n.Values))
+		case *ast.SliceExpr:
+			fmt.Fprintf(&b, " %t %t %t", n.Low != nil, n.High != nil, n.Max != nil)
+		case *ast.ForStmt:
+			fmt.Fprintf(&b, " %t %t %t", n.Init != nil, n.Cond != nil, n.Post != nil)
+		case *ast.IfStmt:
+			fmt.Fprintf(&b, " %t %t", n.Init != nil, n.Else != nil)
+		case *ast.SwitchStmt:
+			fmt.Fprintf(&b, " %t %t", n.Init != nil, n.Tag != nil)
+		case *ast.TypeSwitchStmt:
+			fmt.Fprintf(&b, " %t", n.Init != nil)
+		case *ast.RangeStmt:
+			fmt.Fprintf(&b, " %s %t %t", n.Tok, n.Key != nil, n.Value != nil)
+		case *ast.CaseClause:
+			fmt.Fprintf(&b, " %t", n.List == nil)
+		case *ast.CommClause:
+			fmt.Fprintf(&b, " %t", n.Comm == nil)
+		case *ast.FuncType:
+			fmt.Fprintf(&b, " %t", n.Results != nil)
+		case *ast.Field:
+			fmt.Fprintf(&b, " %d", len(n.Names))
+		case *ast.CompositeLit:
+			fmt.Fprintf(&b, " %t", n.Type != nil)
+		case *ast.CallExpr:
+			fmt.Fprintf(&b, " %t", n.Ellipsis.IsValid())
+		}
+
+		return true
+	})
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package selectivetesting
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestNormalizeFile(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		head string
+		// Whether the header differs, falling back to the whole file.
+		wantHeaderChanged bool
+		wantChanged       []string
+	}{
+		{
+			name: "comments and formatting",
+			base: "package p\n\n// F does things.\nfunc F() int { return 1 }\n",
+			head: "package p\n\n// F does other things.\nfunc F() int {\n\treturn 1 // one\n}\n",
+		},
+		{
+			name:        "embed pattern",
+			base:        "package p\n\nimport \"embed\"\n\n//go:embed a.txt\nvar fs embed.FS\n",
+			head:        "package p\n\nimport \"embed\"\n\n//go:embed b.txt\nvar fs embed.FS\n",
+			wantChanged: []string{"var fs"},
+		},
+		{
+			name:        "embed pattern within group",
+			base:        "package p\n\nimport \"embed\"\n\nvar (\n\t//go:embed a.txt\n\tfs embed.FS\n)\n",
+			head:        "package p\n\nimport \"embed\"\n\nvar (\n\t//go:embed a.txt b.txt\n\tfs embed.FS\n)\n",
+			wantChanged: []string{"var fs"},
+		},
+		{
+			name:        "linkname",
+			base:        "package p\n\nimport _ \"unsafe\"\n\n//go:linkname now runtime.nanotime\nfunc now() int64\n",
+			head:        "package p\n\nimport _ \"unsafe\"\n\n//go:linkname now runtime.walltime\nfunc now() int64\n",
+			wantChanged: []string{"func now"},
+		},
+		{
+			name:        "noinline",
+			base:        "package p\n\nfunc F() int { return 1 }\n",
+			head:        "package p\n\n//go:noinline\nfunc F() int { return 1 }\n",
+			wantChanged: []string{"func F"},
+		},
+		{
+			name:              "build constraint",
+			base:              "//go:build linux\n\npackage p\n\nfunc F() int { return 1 }\n",
+			head:              "//go:build linux || darwin\n\npackage p\n\nfunc F() int { return 1 }\n",
+			wantHeaderChanged: true,
+		},
+		{
+			name:              "cgo preamble",
+			base:              "package p\n\n// #include <stdio.h>\nimport \"C\"\n",
+			head:              "package p\n\n// #include <stdlib.h>\nimport \"C\"\n",
+			wantHeaderChanged: true,
+		},
+		{
+			name:              "cgo preamble within group",
+			base:              "package p\n\nimport (\n\t// #define N 1\n\t\"C\"\n)\n",
+			head:              "package p\n\nimport (\n\t// #define N 2\n\t\"C\"\n)\n",
+			wantHeaderChanged: true,
+		},
+		{
+			name:        "spread call",
+			base:        "package p\n\nfunc F(xs ...any) { G(xs) }\n\nfunc G(xs ...any) {}\n",
+			head:        "package p\n\nfunc F(xs ...any) { G(xs...) }\n\nfunc G(xs ...any) {}\n",
+			wantChanged: []string{"func F"},
+		},
+		{
+			name:        "type or value",
+			base:        "package p\n\nvar x T\n",
+			head:        "package p\n\nvar x = T\n",
+			wantChanged: []string{"var x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseHeader, baseDecls, err := normalizeFile([]byte(tt.base))
+			if err != nil {
+				t.Fatal(err)
+			}
+			headHeader, headDecls, err := normalizeFile([]byte(tt.head))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if headerChanged := baseHeader != headHeader; headerChanged != tt.wantHeaderChanged {
+				t.Errorf("header changed = %t, want %t", headerChanged, tt.wantHeaderChanged)
+			}
+
+			changed := make([]string, 0)
+			for key, headDecl := range headDecls {
+				if baseDecl, ok := baseDecls[key]; !ok || baseDecl.normalized != headDecl.normalized {
+					changed = append(changed, key)
+				}
+			}
+			sort.Strings(changed)
+			if !slices.Equal(changed, tt.wantChanged) {
+				t.Errorf("changed declarations = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
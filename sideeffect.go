@@ -0,0 +1,101 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+// isRepeatable reports whether the object may be declared more than once in a package under the same name.
+func isRepeatable(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Name() == "init" && obj.Type().(*types.Signature).Recv() == nil
+	case *types.Var:
+		return obj.Name() == "_" && !obj.IsField()
+	}
+	return false
+}
+
+// isLoadTimeCode reports whether the definition runs code as soon as its package is imported, which is the case for
+// init functions and package variables initialized through function calls.
+func isLoadTimeCode(info *types.Info, obj types.Object, node ast.Node) bool {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Name() == "init" && obj.Type().(*types.Signature).Recv() == nil
+	case *types.Var:
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || obj.IsField() {
+			return false
+		}
+		for _, value := range spec.Values {
+			if hasCall(info, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasCall(info *types.Info, expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Bodies of function literals only run when called, which is caught by the enclosing call.
+			return false
+		case *ast.CallExpr:
+			// Ginkgo containers and specs are tracked on their own.
+			if _, ok := stringLitArgOf(n); ok && ginkgoNodeKindOf(info, n) != ginkgoNodeNone {
+				return false
+			}
+			if !isConversionOrBuiltin(info, n) {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+func isConversionOrBuiltin(info *types.Info, call *ast.CallExpr) bool {
+	if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+		return true
+	}
+
+	fun := call.Fun
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+	if sel, ok := fun.(*ast.SelectorExpr); ok {
+		fun = sel.Sel
+	}
+	ident, ok := fun.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = info.Uses[ident].(*types.Builtin)
+	return ok
+}
+
+// testsFromSideEffects selects every test of the packages whose load time code has been reached, along with the
+// tests of their importers.
+func (fa *FileAnalyzer) testsFromSideEffects(testedPkgs map[string]*TestedPackage, sideEffectPkgs map[string]string) {
+	for sideEffectPkgPath, objName := range sideEffectPkgs {
+		chain := fa.usageChainOf(objName)
+
+		fa.selectAllTests(testedPkgs, sideEffectPkgPath, chain)
+		for pkgPath := range fa.importersOf(util.NewSet(sideEffectPkgPath), fa.depth) {
+			fa.selectAllTests(testedPkgs, pkgPath, chain)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package selectivetesting
+
+import "testing"
+
+func TestLoadTimeCodeSelectsImporters(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		notableSrc string
+	}{
+		{
+			name:       "init",
+			notableSrc: "package a\n\nfunc init() {\n\tregistry[\"init\"] = true\n}\n",
+		},
+		{
+			name:       "var",
+			notableSrc: "package a\n\nvar registered = register(\"var\")\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestModule(t, map[string]string{
+				"a/a.go": `package a
+
+var registry = map[string]bool{}
+
+func register(name string) bool {
+	registry[name] = true
+	return true
+}
+`,
+				"a/notable.go": tc.notableSrc,
+				"a/a_test.go":  "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n",
+				"b/b.go":       "package b\n\nimport _ \"example.com/m/a\"\n",
+				"b/b_test.go":  "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+				"c/c_test.go":  "package c\n\nimport \"testing\"\n\nfunc TestC(t *testing.T) {}\n",
+			})
+
+			fa := loadTestModule(t, dir, []string{"a/notable.go"}, WithDepth(1))
+			testedPkgs, _ := fa.DetermineTests()
+
+			assertSelected(t, testedPkgs, testModulePath+"/a", "TestA")
+			assertSelected(t, testedPkgs, testModulePath+"/b", "TestB")
+			assertNotSelected(t, testedPkgs, testModulePath+"/c", "TestC")
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package selectivetesting
+
+import (
+	"sort"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+// BlastRadius is the number of tests selected when only a definition or a file changes.
+type BlastRadius struct {
+	// Object name of the definition, empty for files.
+	ObjName   string
+	PkgPath   string
+	FileName  string
+	StartLine int
+	TestCount int
+}
+
+type GraphStats struct {
+	DefinitionCount int
+	// Number of usages between definitions.
+	EdgeCount int
+	// Number of tests, benchmarks, fuzz targets and examples by package path.
+	TestCounts map[string]int
+}
+
+// BlastRadii returns the blast radius of every definition at the configured depth, largest first.
+func (fa *FileAnalyzer) BlastRadii() []BlastRadius {
+	suiteMembers := fa.suiteMembers()
+	reachedTargets := fa.targetsReachedFrom(suiteMembers)
+
+	radii := make([]BlastRadius, 0, len(fa.definitions))
+	for objName, def := range fa.definitions {
+		radii = append(radii, BlastRadius{
+			ObjName:   objName,
+			PkgPath:   def.pkgPath,
+			FileName:  def.fileName,
+			StartLine: def.startLine,
+			TestCount: fa.testCountFrom(reachedTargets[objName], suiteMembers),
+		})
+	}
+	sortBlastRadii(radii)
+	return radii
+}
+
+// FileBlastRadii returns the blast radius of every file with definitions at the configured depth, largest first.
+func (fa *FileAnalyzer) FileBlastRadii() []BlastRadius {
+	suiteMembers := fa.suiteMembers()
+	reachedTargets := fa.targetsReachedFrom(suiteMembers)
+
+	radii := make([]BlastRadius, 0, len(fa.fileObjNames))
+	for fileName, objNames := range fa.fileObjNames {
+		if objNames.Len() == 0 {
+			continue
+		}
+
+		pkgPath := ""
+		targets := util.NewSet[string]()
+		for objName := range objNames {
+			pkgPath = fa.definitions[objName].pkgPath
+			targets.AddFrom(reachedTargets[objName])
+		}
+		radii = append(radii, BlastRadius{
+			PkgPath:   pkgPath,
+			FileName:  fileName,
+			TestCount: fa.testCountFrom(targets, suiteMembers),
+		})
+	}
+	sortBlastRadii(radii)
+	return radii
+}
+
+func sortBlastRadii(radii []BlastRadius) {
+	sort.Slice(radii, func(i, j int) bool {
+		if radii[i].TestCount != radii[j].TestCount {
+			return radii[i].TestCount > radii[j].TestCount
+		}
+		if radii[i].FileName != radii[j].FileName {
+			return radii[i].FileName < radii[j].FileName
+		}
+		return radii[i].ObjName < radii[j].ObjName
+	})
+}
+
+// targetsReachedFrom returns, by definition, the objects selecting tests or running at load time that changing it
+// reaches at the configured depth. Searching back from each of those objects once visits far fewer objects than
+// searching forward from every definition.
+func (fa *FileAnalyzer) targetsReachedFrom(suiteMembers map[string][]suiteMember) map[string]util.Set[string] {
+	reachedTargets := make(map[string]util.Set[string])
+	for targetObjName, def := range fa.definitions {
+		isTarget := def.sideEffect || def.ginkgoNode != nil || def.subtestOf != "" || len(suiteMembers[targetObjName]) > 0 ||
+			fa.testFuncs.Has(targetObjName) || fa.testMainFuncs.Has(targetObjName)
+		if !isTarget {
+			continue
+		}
+
+		for objName := range fa.stepsToUsed([]string{targetObjName}, fa.depth) {
+			targets := util.MapGetOrCreate(reachedTargets, objName, func() util.Set[string] { return util.NewSet[string]() })
+			targets.Add(targetObjName)
+		}
+	}
+	return reachedTargets
+}
+
+// testCountFrom returns the number of unique tests selected by reaching the given objects, leaving changed modules
+// aside.
+func (fa *FileAnalyzer) testCountFrom(targets util.Set[string], suiteMembers map[string][]suiteMember) int {
+	testedPkgs := make(map[string]*TestedPackage)
+	sideEffectPkgs := make(map[string]string)
+	for objName := range targets {
+		def := fa.definitions[objName]
+		if _, ok := sideEffectPkgs[def.pkgPath]; def.sideEffect && !ok {
+			sideEffectPkgs[def.pkgPath] = objName
+		}
+		fa.selectReached(testedPkgs, objName, nil, suiteMembers, nil)
+	}
+	fa.testsFromSideEffects(testedPkgs, sideEffectPkgs)
+	return fa.consolidateTestedPkgs(testedPkgs)
+}
+
+func (fa *FileAnalyzer) GraphStats() GraphStats {
+	stats := GraphStats{
+		DefinitionCount: len(fa.definitions),
+		TestCounts:      make(map[string]int, len(fa.pkgTestUniqNames)),
+	}
+	for _, def := range fa.definitions {
+		stats.EdgeCount += def.usingObjNames.Len()
+	}
+	for pkgPath, names := range fa.pkgTestUniqNames {
+		stats.TestCounts[pkgPath] = names.Len()
+	}
+	return stats
+}
@@ -0,0 +1,64 @@
+package selectivetesting
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlastRadii(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go": `package a
+
+func Base() int {
+	return 1
+}
+
+func Mid() int {
+	return Base() + 1
+}
+
+func Top() int {
+	return Mid() + 1
+}
+`,
+		"a/a_test.go": `package a
+
+import "testing"
+
+func TestBase(t *testing.T) {
+	_ = Base()
+}
+
+func TestTop(t *testing.T) {
+	_ = Top()
+}
+`,
+	})
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(2))
+	fa.DetermineTests()
+	reachedFrom := maps.Clone(fa.reachedFrom)
+
+	counts := make(map[string]int)
+	for _, radius := range fa.BlastRadii() {
+		counts[fa.definitions[radius.ObjName].name] = radius.TestCount
+	}
+	// TestTop is three steps away from Base.
+	for name, want := range map[string]int{"Base": 1, "Mid": 1, "Top": 1, "TestBase": 1} {
+		if counts[name] != want {
+			t.Errorf("blast radius of %s is %d, want %d", name, counts[name], want)
+		}
+	}
+
+	fileCounts := make(map[string]int)
+	for _, radius := range fa.FileBlastRadii() {
+		fileCounts[radius.FileName] = radius.TestCount
+	}
+	if count := fileCounts[filepath.Join(dir, "a", "a.go")]; count != 2 {
+		t.Errorf("blast radius of a.go is %d, want 2", count)
+	}
+
+	if !maps.Equal(fa.reachedFrom, reachedFrom) {
+		t.Errorf("blast radii changed the chains of the last selection")
+	}
+}
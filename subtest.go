@@ -0,0 +1,111 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+// searchSubtests records the subtests with literal names run directly by the tests of the package, so that only the
+// subtests reaching changed code can be run.
+func (fa *FileAnalyzer) searchSubtests(pkg *packages.Package) {
+	for _, astFile := range pkg.Syntax {
+		file := pkg.Fset.File(astFile.Pos())
+		if _, removed := fa.removedFiles[file.Name()]; removed || !strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+
+		for _, d := range astFile.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Body == nil {
+				continue
+			}
+			obj := pkg.TypesInfo.Defs[decl.Name]
+			if obj == nil {
+				continue
+			}
+			parentObjName := fa.objNameOf(obj)
+			if !fa.testFuncs.Has(parentObjName) || testKindOf(obj.Name()) != testKindTest {
+				continue
+			}
+			fa.addSubtests(pkg, file, decl.Body, parentObjName)
+		}
+	}
+}
+
+func (fa *FileAnalyzer) addSubtests(pkg *packages.Package, file *token.File, body *ast.BlockStmt, parentObjName string) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isSubtestRun(pkg.TypesInfo, call) {
+			return true
+		}
+		// Subtests without literal names cannot be selected, so they are left to their parent test as a whole along
+		// with everything they run.
+		name, ok := stringLitArgOf(call)
+		if !ok || strings.Contains(name, "/") {
+			return false
+		}
+
+		pkgPath := strings.TrimSuffix(pkg.PkgPath, "_test")
+		startLine := file.Line(call.Pos())
+
+		fa.putDefinition("subtest "+parentObjName+" "+strconv.Quote(name)+" "+file.Name()+":"+strconv.Itoa(startLine), &definition{
+			node:           call,
+			pkgPath:        pkgPath,
+			name:           rewriteSubtestName(name),
+			fileName:       file.Name(),
+			startLine:      startLine,
+			endLine:        file.Line(call.End()),
+			usedByObjNames: util.NewSet[string](),
+			usingObjNames:  util.NewSet[string](),
+			subtestOf:      parentObjName,
+		})
+		// Nested subtests are left to the subtest running them.
+		return false
+	})
+}
+
+// isSubtestRun reports whether the call is a (*testing.T).Run call.
+func isSubtestRun(info *types.Info, call *ast.CallExpr) bool {
+	obj, ok := calleeOf(info, call)
+	if !ok || len(call.Args) != 2 || obj.Name() != "Run" {
+		return false
+	}
+	recv := obj.Type().(*types.Signature).Recv()
+	return recv != nil && types.TypeString(recv.Type(), nil) == "*testing.T"
+}
+
+// rewriteSubtestName rewrites the name of the subtest the same way the testing package does before matching it.
+func rewriteSubtestName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			sb.WriteByte('_')
+		case !strconv.IsPrint(r):
+			quoted := strconv.QuoteRune(r)
+			sb.WriteString(quoted[1 : len(quoted)-1])
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// addSubtest selects a subtest run by the test.
+func (tp *TestedPackage) addSubtest(testName, subtestName string) {
+	// The test is already run with every subtest.
+	if tp.Names.Has("*") || (tp.Names.Has(testName) && tp.Subtests[testName] == nil) {
+		return
+	}
+	tp.addName(testName)
+
+	subtestNames := util.MapGetOrCreate(tp.Subtests, testName, func() util.Set[string] { return util.NewSet[string]() })
+	subtestNames.Add(subtestName)
+}
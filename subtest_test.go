@@ -0,0 +1,61 @@
+package selectivetesting
+
+import (
+	"testing"
+)
+
+func TestSubtestsWithinComputedSubtests(t *testing.T) {
+	tests := []struct {
+		name    string
+		subtest string
+	}{
+		{name: "computed name", subtest: "tc.name"},
+		{name: "name with slash", subtest: `"a/b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestModule(t, map[string]string{
+				"p/p.go": "package p\n\nfunc A() int { return 1 }\n",
+				"p/p_test.go": `package p
+
+import "testing"
+
+func TestParent(t *testing.T) {
+	for _, tc := range []struct{ name string }{{name: "x"}} {
+		t.Run(` + tt.subtest + `, func(t *testing.T) {
+			t.Run("inner", func(t *testing.T) { _ = A() })
+		})
+	}
+}
+`,
+			})
+
+			testedPkgs, _ := loadTestModule(t, dir, []string{"p/p.go"}).DetermineTests()
+			testedPkg := testedPkgs[testModulePath+"/p"]
+			if testedPkg == nil || !testedPkg.Has("TestParent") {
+				t.Fatalf("TestParent is not selected")
+			}
+			if subtests := testedPkg.Subtests["TestParent"]; subtests.Len() > 0 {
+				t.Errorf("subtests of TestParent = %v, want none", subtests.ToSlice())
+			}
+		})
+	}
+}
+
+func TestRewriteSubtestName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "simple", want: "simple"},
+		{name: "with space", want: "with_space"},
+		{name: "tab\there", want: "tab_here"},
+		{name: "bell\a", want: `bell\a`},
+		{name: "ünïcode", want: "ünïcode"},
+	}
+	for _, tt := range tests {
+		if got := rewriteSubtestName(tt.name); got != tt.want {
+			t.Errorf("rewriteSubtestName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
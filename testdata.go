@@ -0,0 +1,142 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+	"golang.org/x/tools/go/packages"
+)
+
+const testdataDirName = "testdata"
+
+// searchStringLits records the string literals within the definitions of test files, which may refer to testdata
+// files.
+func (fa *FileAnalyzer) searchStringLits(pkg *packages.Package) {
+	for _, astFile := range pkg.Syntax {
+		file := pkg.Fset.File(astFile.Pos())
+		if _, removed := fa.removedFiles[file.Name()]; removed || !strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+
+		ast.Inspect(astFile, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil || value == "" {
+				return true
+			}
+
+			for _, objName := range fa.userObjNamesAt(file.Name(), lit.Pos()) {
+				def := fa.definitions[objName]
+				if def.stringLits == nil {
+					def.stringLits = util.NewSet[string]()
+				}
+				def.stringLits.Add(value)
+			}
+			return true
+		})
+	}
+}
+
+// queueUpTestdata queues up the definitions of test files referring to the testdata file, or every test of the
+// package owning the testdata directory when none of them does.
+func (fa *FileAnalyzer) queueUpTestdata(fileName string, addToQueue func(string)) {
+	pkgPath, relPath, ok := fa.testdataOwnerOf(fileName)
+	if !ok {
+		return
+	}
+
+	if objName, ok := fa.fuzzTargetOf(pkgPath, relPath); ok {
+		addToQueue(objName)
+		return
+	}
+
+	found := false
+	for objName := range fa.pkgObjNames[pkgPath] {
+		def := fa.definitions[objName]
+		for lit := range def.stringLits {
+			if refersTo(lit, relPath) {
+				addToQueue(objName)
+				found = true
+				break
+			}
+		}
+	}
+	if found {
+		return
+	}
+
+	// TestMain is left out, as it is not what changed even though it runs every test.
+	for objName := range fa.pkgObjNames[pkgPath] {
+		if fa.testFuncs.Has(objName) {
+			addToQueue(objName)
+		}
+	}
+}
+
+// testdataOwnerOf returns the package owning the testdata directory the file is in, along with the slash-separated
+// path of the file relative to the package directory.
+func (fa *FileAnalyzer) testdataOwnerOf(fileName string) (string, string, bool) {
+	elems := strings.Split(filepath.ToSlash(fileName), "/")
+	for i, elem := range elems {
+		if elem != testdataDirName {
+			continue
+		}
+		pkgDir := filepath.FromSlash(strings.Join(elems[:i], "/"))
+		for pkgPath, dir := range fa.pkgDirs {
+			if dir == pkgDir {
+				return pkgPath, strings.Join(elems[i:], "/"), true
+			}
+		}
+		// Only the outermost testdata directory counts.
+		return "", "", false
+	}
+	return "", "", false
+}
+
+// fuzzTargetOf returns the object name of the fuzz target whose seed corpus contains the file, given by its path
+// relative to the package directory.
+func (fa *FileAnalyzer) fuzzTargetOf(pkgPath, relPath string) (string, bool) {
+	elems := strings.Split(relPath, "/")
+	if len(elems) < 4 || elems[0] != testdataDirName || elems[1] != "fuzz" {
+		return "", false
+	}
+	objName, ok := fa.pkgLocalObjNames[pkgPath][elems[2]]
+	if !ok || !fa.testFuncs.Has(objName) || testKindOf(elems[2]) != testKindFuzz {
+		return "", false
+	}
+	return objName, true
+}
+
+// testsFromFuzzCorpora reports the seed corpus files of the fuzz targets among the notable files.
+func (fa *FileAnalyzer) testsFromFuzzCorpora(testedPkgs map[string]*TestedPackage) {
+	for notableFileName := range fa.notableFileNames {
+		pkgPath, relPath, ok := fa.testdataOwnerOf(notableFileName)
+		if !ok {
+			continue
+		}
+		objName, ok := fa.fuzzTargetOf(pkgPath, relPath)
+		if !ok {
+			continue
+		}
+
+		testedPkg := getTestedPkg(testedPkgs, pkgPath)
+		fuzzName := fa.definitions[objName].name
+		corpusFiles := util.MapGetOrCreate(testedPkg.FuzzCorpora, fuzzName, func() util.Set[string] { return util.NewSet[string]() })
+		corpusFiles.Add(relPath)
+	}
+}
+
+// refersTo reports whether the string literal is the path of the file relative to the package directory or any of
+// its trailing parts, such as its base name.
+func refersTo(lit, relPath string) bool {
+	cleanLit := path.Clean(filepath.ToSlash(lit))
+	return cleanLit != "." && cleanLit != testdataDirName && strings.HasSuffix("/"+relPath, "/"+cleanLit)
+}
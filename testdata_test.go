@@ -0,0 +1,67 @@
+package selectivetesting
+
+import "testing"
+
+func TestRefersTo(t *testing.T) {
+	for _, tc := range []struct {
+		lit     string
+		relPath string
+		want    bool
+	}{
+		{lit: "testdata/a.json", relPath: "testdata/a.json", want: true},
+		{lit: "a.json", relPath: "testdata/a.json", want: true},
+		{lit: "cases/a.json", relPath: "testdata/cases/a.json", want: true},
+		{lit: "./testdata/a.json", relPath: "testdata/a.json", want: true},
+		{lit: "testdata/cases/../a.json", relPath: "testdata/a.json", want: true},
+		{lit: "b.json", relPath: "testdata/a.json", want: false},
+		{lit: "ta/a.json", relPath: "testdata/a.json", want: false},
+		{lit: "other/a.json", relPath: "testdata/a.json", want: false},
+		{lit: "", relPath: "testdata/a.json", want: false},
+		{lit: ".", relPath: "testdata/a.json", want: false},
+		{lit: "testdata", relPath: "testdata/a.json", want: false},
+		{lit: "testdata/", relPath: "testdata/a.json", want: false},
+	} {
+		t.Run(tc.lit+" "+tc.relPath, func(t *testing.T) {
+			if got := refersTo(tc.lit, tc.relPath); got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnreferencedTestdataSelectsEveryTest(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":            "package a\n",
+		"a/testdata/x.json": "{}\n",
+		"a/main_test.go":    "package a\n\nimport (\n\t\"os\"\n\t\"testing\"\n)\n\nfunc TestMain(m *testing.M) { os.Exit(m.Run()) }\n",
+		"a/a_test.go":       "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n\nfunc TestB(t *testing.T) {}\n",
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/testdata/x.json"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg == nil || !testedPkg.Has("TestA") || !testedPkg.Has("TestB") {
+		t.Fatalf("every test is not selected")
+	}
+	if testedPkg.ByTestMain {
+		t.Errorf("tests are selected through TestMain, which did not change")
+	}
+}
+
+func TestFuzzCorpusSelectsItsTarget(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":                      "package a\n",
+		"a/testdata/fuzz/FuzzA/seed1": "go test fuzz v1\nint(1)\n",
+		"a/a_test.go":                 "package a\n\nimport \"testing\"\n\nfunc FuzzA(f *testing.F) {}\n\nfunc FuzzB(f *testing.F) {}\n\nfunc TestA(t *testing.T) {}\n",
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/testdata/fuzz/FuzzA/seed1"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "FuzzA")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "FuzzB", "TestA")
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg != nil && !testedPkg.FuzzCorpora["FuzzA"].Has("testdata/fuzz/FuzzA/seed1") {
+		t.Errorf("got fuzz corpora %v, want the seed of FuzzA", testedPkg.FuzzCorpora)
+	}
+}
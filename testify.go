@@ -0,0 +1,151 @@
+package selectivetesting
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+const testifySuitePkgPath = "github.com/stretchr/testify/suite"
+
+// Methods testify calls around the test methods of a suite.
+var testifyLifecycleMethods = util.NewSet(
+	"SetupSuite",
+	"TearDownSuite",
+	"SetupTest",
+	"TearDownTest",
+	"BeforeTest",
+	"AfterTest",
+	"HandleStats",
+	"SetupSubTest",
+	"TearDownSubTest",
+)
+
+type testifySuite struct {
+	// Names of the test methods by their object names.
+	Methods map[string]string `json:"methods"`
+	// Object names of the lifecycle methods.
+	Lifecycles []string `json:"lifecycles"`
+}
+
+type suiteMember struct {
+	entryObjName string
+	// Name of the test method, or "*" for lifecycle methods.
+	methodName string
+}
+
+func isSuiteRun(info *types.Info, call *ast.CallExpr) bool {
+	obj, ok := calleeOf(info, call)
+	return ok && len(call.Args) == 2 && obj.Pkg() != nil && obj.Pkg().Path() == testifySuitePkgPath && obj.Name() == "Run"
+}
+
+func (fa *FileAnalyzer) newTestifySuite(t types.Type) *testifySuite {
+	if t == nil {
+		return nil
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	suite := &testifySuite{Methods: make(map[string]string)}
+	// Methods promoted from embedded suites are run as well.
+	methodSet := types.NewMethodSet(types.NewPointer(named))
+	for i := 0; i < methodSet.Len(); i++ {
+		method := methodSet.At(i).Obj().(*types.Func)
+		objName := fa.objNameOf(method)
+		if _, ok := fa.definitions[objName]; !ok {
+			continue
+		}
+
+		switch {
+		case testifyLifecycleMethods.Has(method.Name()):
+			suite.Lifecycles = append(suite.Lifecycles, objName)
+		case strings.HasPrefix(method.Name(), "Test") && isNiladic(method):
+			suite.Methods[objName] = method.Name()
+		}
+	}
+	return suite
+}
+
+func isNiladic(f *types.Func) bool {
+	sig := f.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
+
+// suiteMembers indexes the entry tests of the suites by the object names of their methods.
+func (fa *FileAnalyzer) suiteMembers() map[string][]suiteMember {
+	members := make(map[string][]suiteMember)
+	for entryObjName, suite := range fa.suites {
+		for objName, methodName := range suite.Methods {
+			members[objName] = append(members[objName], suiteMember{entryObjName: entryObjName, methodName: methodName})
+		}
+		for _, objName := range suite.Lifecycles {
+			members[objName] = append(members[objName], suiteMember{entryObjName: entryObjName, methodName: "*"})
+		}
+	}
+	return members
+}
+
+// suiteMethodNamesOf returns the names of the test methods of the suites run by each test of the package.
+func (fa *FileAnalyzer) suiteMethodNamesOf(pkgPath string) map[string]util.Set[string] {
+	methodNames := make(map[string]util.Set[string])
+	for entryObjName, suite := range fa.suites {
+		def := fa.definitions[entryObjName]
+		if def == nil || def.pkgPath != pkgPath {
+			continue
+		}
+		names := util.MapGetOrCreate(methodNames, def.name, func() util.Set[string] { return util.NewSet[string]() })
+		for _, methodName := range suite.Methods {
+			names.Add(methodName)
+		}
+	}
+	return methodNames
+}
+
+// addSuiteMethod selects a test method of the suite run by the test, "*" selecting all of them.
+func (tp *TestedPackage) addSuiteMethod(testName, methodName string) {
+	// The test is already run with every method of its suite.
+	if tp.Names.Has("*") || (tp.Names.Has(testName) && tp.SuiteMethods[testName] == nil) {
+		return
+	}
+	tp.addName(testName)
+
+	if methodName == "*" {
+		delete(tp.SuiteMethods, testName)
+		return
+	}
+	methodNames := util.MapGetOrCreate(tp.SuiteMethods, testName, func() util.Set[string] { return util.NewSet[string]() })
+	methodNames.Add(methodName)
+}
+
+// consolidateSuites drops the method filters of suites whose methods are all selected, unless other suites of the
+// package are still filtered. As testify applies the same filter to every suite, the filters of whole suites are
+// expanded to all of their methods in that case.
+func (fa *FileAnalyzer) consolidateSuites(pkgPath string, testedPkg *TestedPackage) {
+	if len(testedPkg.SuiteMethods) == 0 {
+		return
+	}
+
+	allMethodNames := fa.suiteMethodNamesOf(pkgPath)
+	for testName, methodNames := range testedPkg.SuiteMethods {
+		if methodNames.Len() == allMethodNames[testName].Len() {
+			delete(testedPkg.SuiteMethods, testName)
+		}
+	}
+	if len(testedPkg.SuiteMethods) == 0 {
+		return
+	}
+
+	for testName, methodNames := range allMethodNames {
+		_, filtered := testedPkg.SuiteMethods[testName]
+		if !filtered && (testedPkg.Names.Has("*") || testedPkg.Names.Has(testName)) {
+			testedPkg.SuiteMethods[testName] = util.SetFrom(methodNames.ToSlice())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package selectivetesting
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuiteMethodSelection(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"go.mod": `module example.com/m
+
+go 1.21
+
+require github.com/stretchr/testify v1.0.0
+
+replace github.com/stretchr/testify => ./testify
+`,
+		"testify/go.mod":         "module github.com/stretchr/testify\n\ngo 1.21\n",
+		"testify/suite/suite.go": "package suite\n\nimport \"testing\"\n\ntype Suite struct{}\n\nfunc Run(t *testing.T, s interface{}) {}\n",
+		"a/a.go":                 "package a\n\nfunc A() int { return 1 }\n",
+		"a/a_test.go": `package a
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ASuite struct {
+	suite.Suite
+}
+
+func (s *ASuite) TestOne() { A() }
+
+func (s *ASuite) TestTwo() {}
+
+func TestASuite(t *testing.T) {
+	suite.Run(t, new(ASuite))
+}
+
+func TestB(t *testing.T) {}
+`,
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestASuite")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "TestB")
+
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg == nil {
+		return
+	}
+	methodNames := testedPkg.SuiteMethods["TestASuite"].ToSlice()
+	slices.Sort(methodNames)
+	if !slices.Equal(methodNames, []string{"TestOne"}) {
+		t.Errorf("got suite methods %q, want [TestOne]", methodNames)
+	}
+}
@@ -0,0 +1,156 @@
+package selectivetesting
+
+import (
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+type testKind int
+
+const (
+	testKindTest testKind = iota
+	testKindBenchmark
+	testKindFuzz
+	testKindExample
+)
+
+var testKinds = []testKind{testKindTest, testKindBenchmark, testKindFuzz, testKindExample}
+
+// Prefix of the function names of each kind, as recognized by go test.
+func (kind testKind) prefix() string {
+	switch kind {
+	case testKindBenchmark:
+		return "Benchmark"
+	case testKindFuzz:
+		return "Fuzz"
+	case testKindExample:
+		return "Example"
+	}
+	return "Test"
+}
+
+// Name of the testing type a function of the kind takes a pointer to.
+func (kind testKind) paramTypeName() string {
+	switch kind {
+	case testKindBenchmark:
+		return "B"
+	case testKindFuzz:
+		return "F"
+	}
+	return "T"
+}
+
+// testKindOf tells the kind of a function already known to be run by go test.
+func testKindOf(name string) testKind {
+	for _, kind := range testKinds[1:] {
+		if strings.HasPrefix(name, kind.prefix()) {
+			return kind
+		}
+	}
+	return testKindTest
+}
+
+func (tp *TestedPackage) namesOf(kind testKind) *util.Set[string] {
+	switch kind {
+	case testKindBenchmark:
+		return &tp.Benchmarks
+	case testKindFuzz:
+		return &tp.Fuzzes
+	case testKindExample:
+		return &tp.Examples
+	}
+	return &tp.Names
+}
+
+// testKindsOf returns the kinds of functions the package has for go test to run.
+func (fa *FileAnalyzer) testKindsOf(pkgPath string) []testKind {
+	kinds := make([]testKind, 0, len(testKinds))
+	for _, kind := range testKinds {
+		if fa.testCountOf(pkgPath, kind) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+func (fa *FileAnalyzer) testCountOf(pkgPath string, kind testKind) int {
+	count := 0
+	for name := range fa.pkgTestUniqNames[pkgPath] {
+		if testKindOf(name) == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// recordTestFunc records the function if go test would run it, or the reason it would not if it only looks like it.
+func (fa *FileAnalyzer) recordTestFunc(f *types.Func, runnableExamples util.Set[string]) {
+	if f.Type().(*types.Signature).Recv() != nil {
+		return
+	}
+
+	objName := fa.objNameOf(f)
+	if f.Name() == "TestMain" {
+		if hasTestingParam(f, "M") {
+			fa.testMainFuncs.Add(objName)
+		} else {
+			fa.rejectedTestFuncs[objName] = "signature is not func(*testing.M)"
+		}
+		return
+	}
+
+	for _, kind := range testKinds {
+		if !strings.HasPrefix(f.Name(), kind.prefix()) {
+			continue
+		}
+
+		switch {
+		case !hasTestName(f.Name(), kind.prefix()):
+			fa.rejectedTestFuncs[objName] = "name continues with a lowercase letter after " + kind.prefix()
+		case kind == testKindExample:
+			// Examples without output comments are only compiled.
+			if !runnableExamples.Has(f.Name()) {
+				fa.rejectedTestFuncs[objName] = "example has no output comment"
+				return
+			}
+			fa.addTestFunc(objName)
+		case !hasTestingParam(f, kind.paramTypeName()):
+			fa.rejectedTestFuncs[objName] = "signature is not func(*testing." + kind.paramTypeName() + ")"
+		default:
+			fa.addTestFunc(objName)
+		}
+		return
+	}
+}
+
+// hasTestName follows go test in requiring the name not to continue with a lowercase letter, as in Testify.
+func hasTestName(name, prefix string) bool {
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// hasTestingParam reports whether the function takes a single pointer to the given type of the testing package and
+// returns nothing.
+func hasTestingParam(f *types.Func, typeName string) bool {
+	sig := f.Type().(*types.Signature)
+	if sig.Recv() != nil || sig.Params().Len() != 1 || sig.Results().Len() != 0 {
+		return false
+	}
+	ptr, ok := sig.Params().At(0).Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "testing" && obj.Name() == typeName
+}
@@ -0,0 +1,90 @@
+package selectivetesting
+
+import "testing"
+
+func TestTestKinds(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"a/a_test.go": `package a
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestA(t *testing.T) { A() }
+
+func BenchmarkA(b *testing.B) { A() }
+
+func FuzzA(f *testing.F) { A() }
+
+func ExampleA() {
+	fmt.Println(A())
+	// Output: 1
+}
+
+func ExampleA_compiled() {
+	fmt.Println(A())
+}
+
+// Unrelated functions of each kind keep the selected ones from standing for every one of them.
+func TestB(t *testing.T) {}
+
+func BenchmarkB(b *testing.B) {}
+
+func FuzzB(f *testing.F) {}
+
+func ExampleB() {
+	fmt.Println(2)
+	// Output: 2
+}
+`,
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestA", "BenchmarkA", "FuzzA", "ExampleA")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "ExampleA_compiled", "TestB", "BenchmarkB", "FuzzB", "ExampleB")
+
+	testedPkg := testedPkgs[testModulePath+"/a"]
+	if testedPkg != nil && (!testedPkg.Benchmarks.Has("BenchmarkA") || !testedPkg.Fuzzes.Has("FuzzA") ||
+		!testedPkg.Examples.Has("ExampleA")) {
+		t.Errorf("got benchmarks %v, fuzz targets %v and examples %v, want each in its own set",
+			testedPkg.Benchmarks, testedPkg.Fuzzes, testedPkg.Examples)
+	}
+}
+
+func TestLookalikeTestFuncsAreRejected(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"a/a_test.go": `package a
+
+import "testing"
+
+func TestA(t *testing.T) { A() }
+
+func Testify(t *testing.T) { A() }
+
+func TestHelper(t *testing.T, n int) { A() }
+
+func TestB(t *testing.T) {}
+`,
+	})
+
+	fa := loadTestModule(t, dir, []string{"a/a.go"}, WithDepth(1))
+	testedPkgs, _ := fa.DetermineTests()
+
+	assertSelected(t, testedPkgs, testModulePath+"/a", "TestA")
+	assertNotSelected(t, testedPkgs, testModulePath+"/a", "Testify", "TestHelper")
+
+	for name, wantReason := range map[string]string{
+		"Testify":    "name continues with a lowercase letter after Test",
+		"TestHelper": "signature is not func(*testing.T)",
+	} {
+		def := definitionNamed(t, fa, testModulePath+"/a", name)
+		if reason := fa.rejectedTestFuncs[fa.objNameOf(def.obj)]; reason != wantReason {
+			t.Errorf("got %s rejected for %q, want %q", name, reason, wantReason)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package selectivetesting
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ezraisw/go-selectivetesting/internal/util"
+)
+
+// UntestedDefinition is a definition outside of test files that no test reaches within the configured depth.
+type UntestedDefinition struct {
+	ObjName   string
+	PkgPath   string
+	FileName  string
+	StartLine int
+}
+
+// UntestedDefinitions returns the definitions whose changes would select no test, sorted by package path, file name
+// and line. When changedOnly is set, only definitions changed in the notable files are considered.
+func (fa *FileAnalyzer) UntestedDefinitions(changedOnly bool) []UntestedDefinition {
+	reached := fa.reachedByTests()
+
+	candidates := util.NewSet[string]()
+	if changedOnly {
+		for notableFileName := range fa.notableFileNames {
+			fa.queueUpFile(notableFileName, func(objName string) { candidates.Add(objName) })
+		}
+	} else {
+		for objName := range fa.definitions {
+			candidates.Add(objName)
+		}
+	}
+
+	untested := make([]UntestedDefinition, 0)
+	for objName := range candidates {
+		def := fa.definitions[objName]
+		if def == nil || reached.Has(objName) || strings.HasSuffix(def.fileName, "_test.go") {
+			continue
+		}
+		if _, removed := fa.removedFiles[def.fileName]; removed {
+			continue
+		}
+		untested = append(untested, UntestedDefinition{
+			ObjName:   objName,
+			PkgPath:   def.pkgPath,
+			FileName:  def.fileName,
+			StartLine: def.startLine,
+		})
+	}
+
+	sort.Slice(untested, func(i, j int) bool {
+		if untested[i].PkgPath != untested[j].PkgPath {
+			return untested[i].PkgPath < untested[j].PkgPath
+		}
+		if untested[i].FileName != untested[j].FileName {
+			return untested[i].FileName < untested[j].FileName
+		}
+		if untested[i].StartLine != untested[j].StartLine {
+			return untested[i].StartLine < untested[j].StartLine
+		}
+		return untested[i].ObjName < untested[j].ObjName
+	})
+	return untested
+}
+
+// reachedByTests returns the object names of the definitions used by any test within the configured depth, including
+// the tests themselves.
+func (fa *FileAnalyzer) reachedByTests() util.Set[string] {
+	reached := util.NewSet[string]()
+	frontier := make([]string, 0)
+	roots := append(fa.testFuncs.ToSlice(), fa.testMainFuncs.ToSlice()...)
+
+	// Load time code selects every test of its package and importers instead.
+	testedPkgs := make(map[string]bool)
+	for objName, def := range fa.definitions {
+		if !def.sideEffect {
+			continue
+		}
+		tested, ok := testedPkgs[def.pkgPath]
+		if !ok {
+			tested = fa.pkgTestUniqNames[def.pkgPath].Len() > 0
+			for importerPkgPath := range fa.importersOf(util.NewSet(def.pkgPath), fa.depth) {
+				tested = tested || fa.pkgTestUniqNames[importerPkgPath].Len() > 0
+			}
+			testedPkgs[def.pkgPath] = tested
+		}
+		if tested {
+			roots = append(roots, objName)
+		}
+	}
+
+	for _, rootObjName := range roots {
+		frontier = append(frontier, fa.nodesRunBy(rootObjName)...)
+	}
+	for objName := range fa.stepsToUsed(frontier, fa.depth) {
+		reached.Add(objName)
+	}
+	return reached
+}
@@ -0,0 +1,31 @@
+package selectivetesting
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUntestedDefinitions(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a/a.go":      "package a\n\nfunc A() int { return 1 }\n\nfunc Unused() int { return 2 }\n",
+		"a/b.go":      "package a\n\nfunc B() int { return 3 }\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) { A() }\n",
+	})
+	fa := loadTestModule(t, dir, []string{"a/b.go"}, WithDepth(1))
+
+	for _, tc := range []struct {
+		changedOnly bool
+		wantNames   []string
+	}{
+		{changedOnly: false, wantNames: []string{"Unused", "B"}},
+		{changedOnly: true, wantNames: []string{"B"}},
+	} {
+		names := make([]string, 0)
+		for _, untested := range fa.UntestedDefinitions(tc.changedOnly) {
+			names = append(names, fa.definitions[untested.ObjName].name)
+		}
+		if !slices.Equal(names, tc.wantNames) {
+			t.Errorf("got untested definitions %q with changed only %t, want %q", names, tc.changedOnly, tc.wantNames)
+		}
+	}
+}